@@ -305,3 +305,25 @@ func BenchmarkPrefixSuffixRegexpMismatch(b *testing.B) {
 		_ = m.Match(f)
 	}
 }
+
+func BenchmarkSetMatch(b *testing.B) {
+	s := NewSet('/')
+	for i := range 5000 {
+		s.Add(fmt.Sprintf("src/team%d/**", i))
+	}
+	for b.Loop() {
+		_ = s.Match("src/team4242/pkg/main.go")
+	}
+}
+
+func BenchmarkSetLoopMatch(b *testing.B) {
+	var globs []*Glob
+	for i := range 5000 {
+		globs = append(globs, Must(fmt.Sprintf("src/team%d/**", i), '/'))
+	}
+	for b.Loop() {
+		for _, g := range globs {
+			_ = g.Match("src/team4242/pkg/main.go")
+		}
+	}
+}
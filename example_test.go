@@ -2,6 +2,7 @@ package glob_test
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/kenshaw/glob"
 )
@@ -135,3 +136,37 @@ func Example() {
 	// false
 	// false
 }
+
+func ExampleGlob_FindSubmatch() {
+	g := glob.Must("src/*/{main,init}.go", '/')
+	fmt.Printf("%q\n", g.FindSubmatch("src/api/main.go"))
+	fmt.Printf("%q\n", g.FindSubmatch("src/api/v1/main.go"))
+	// Output:
+	// ["api" "main"]
+	// []
+}
+
+func ExampleGlob_Replace() {
+	g := glob.Must("*.tar.gz")
+	fmt.Println(g.Replace("logs.tar.gz", "$1.tgz"))
+	fmt.Println(g.Replace("logs.zip", "$1.tgz"))
+	// Output:
+	// logs.tgz
+	// logs.zip
+}
+
+func ExampleExpandBraces() {
+	v, err := glob.ExpandBraces("src/{a,b}/{x,y}.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(v)
+	v, err = glob.ExpandBraces("log.{01..03}.gz")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(v)
+	// Output:
+	// [src/a/x.go src/a/y.go src/b/x.go src/b/y.go]
+	// [log.01.gz log.02.gz log.03.gz]
+}
@@ -0,0 +1,177 @@
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+
+	"github.com/kenshaw/glob/syntax"
+)
+
+// DefaultExpandLimit is the maximum number of strings produced by
+// [ExpandBraces].
+const DefaultExpandLimit = 10000
+
+// ErrExpandLimit is the error returned when the expansion of a pattern would
+// produce more strings than the limit.
+var ErrExpandLimit = errors.New("expansion limit exceeded")
+
+// ExpandBraces expands the brace alternatives (`{a,b}`) and sequences
+// (`{1..3}`) of the pattern into the strings they produce, as with bash's
+// brace expansion.
+// For example, `src/{a,b}/{x,y}.go` expands to `src/a/x.go`, `src/a/y.go`,
+// `src/b/x.go` and `src/b/y.go`. Escapes are removed from the strings.
+//
+// Returns [ErrExpandLimit] when the pattern would expand to more than
+// [DefaultExpandLimit] strings, and an error when the pattern contains
+// wildcards, which do not expand to concrete strings.
+func ExpandBraces(pattern string) ([]string, error) {
+	return ExpandBracesLimit(pattern, DefaultExpandLimit)
+}
+
+// ExpandBracesLimit expands the pattern as with [ExpandBraces], returning
+// [ErrExpandLimit] when the pattern would expand to more than limit strings.
+// A limit of zero or less means no limit, other than the number of strings
+// fitting in an int.
+func ExpandBracesLimit(pattern string, limit int) ([]string, error) {
+	tree, err := parseExpand(pattern)
+	if err != nil {
+		return nil, err
+	}
+	n := expandCount(tree, limit)
+	if n == math.MaxInt || limit > 0 && n > limit {
+		return nil, fmt.Errorf("pattern %q: %w", pattern, ErrExpandLimit)
+	}
+	var v []string
+	if limit > 0 {
+		v = make([]string, 0, n)
+	}
+	expandNodes([]*syntax.Node{tree}, "", func(s string) bool {
+		v = append(v, s)
+		return true
+	})
+	return v, nil
+}
+
+// ExpandBracesSeq returns a sequence of the strings the pattern expands to,
+// as with [ExpandBraces]. The strings are produced as the sequence is
+// iterated, so the expansion is not limited in size. An error parsing the
+// pattern is yielded once.
+func ExpandBracesSeq(pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		tree, err := parseExpand(pattern)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		expandNodes([]*syntax.Node{tree}, "", func(s string) bool {
+			return yield(s, nil)
+		})
+	}
+}
+
+// parseExpand parses the pattern, checking that it can be expanded.
+func parseExpand(pattern string) (*syntax.Node, error) {
+	tree, err := syntax.Parse(syntax.NewLexer(pattern))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpand(tree); err != nil {
+		return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+	return tree, nil
+}
+
+// checkExpand checks that the node only contains text, alternatives and
+// sequences.
+func checkExpand(n *syntax.Node) error {
+	switch n.Type {
+	case syntax.Nothing, syntax.Pattern, syntax.AnyOf, syntax.Text, syntax.Sequence:
+	default:
+		return fmt.Errorf("cannot expand %s", n.Type)
+	}
+	for _, c := range n.Children {
+		if err := checkExpand(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandCount returns the number of strings the node expands to, stopping
+// early once the count exceeds the limit. The count saturates at
+// [math.MaxInt].
+func expandCount(n *syntax.Node, limit int) int {
+	over := func(v int) bool {
+		return v == math.MaxInt || limit > 0 && v > limit
+	}
+	switch n.Type {
+	case syntax.Pattern:
+		v := 1
+		for _, c := range n.Children {
+			if v = mulSat(v, expandCount(c, limit)); over(v) {
+				return v
+			}
+		}
+		return v
+	case syntax.AnyOf:
+		var v int
+		for _, c := range n.Children {
+			if v = addSat(v, expandCount(c, limit)); over(v) {
+				return v
+			}
+		}
+		return v
+	case syntax.Sequence:
+		return n.Value.(syntax.SequenceData).Len()
+	}
+	return 1
+}
+
+// addSat returns a+b for non-negative a and b, saturating at [math.MaxInt].
+func addSat(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// mulSat returns a*b for non-negative a and b, saturating at [math.MaxInt].
+func mulSat(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// expandNodes calls yield with prefix followed by each expansion of the
+// nodes, in order, until yield returns false.
+func expandNodes(nodes []*syntax.Node, prefix string, yield func(string) bool) bool {
+	if len(nodes) == 0 {
+		return yield(prefix)
+	}
+	n, rest := nodes[0], nodes[1:]
+	switch n.Type {
+	case syntax.Text:
+		return expandNodes(rest, prefix+n.Value.(syntax.TextData).Text, yield)
+	case syntax.Pattern:
+		return expandNodes(append(n.Children[:len(n.Children):len(n.Children)], rest...), prefix, yield)
+	case syntax.AnyOf:
+		for _, c := range n.Children {
+			if !expandNodes(append([]*syntax.Node{c}, rest...), prefix, yield) {
+				return false
+			}
+		}
+		return true
+	case syntax.Sequence:
+		d := n.Value.(syntax.SequenceData)
+		for i := range d.Len() {
+			if !expandNodes(rest, prefix+d.Value(i), yield) {
+				return false
+			}
+		}
+		return true
+	}
+	return expandNodes(rest, prefix, yield)
+}
@@ -0,0 +1,90 @@
+package glob
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestExpandPattern(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		exp []string
+	}{
+		{``, []string{``}},
+		{`abc`, []string{`abc`}},
+		{`src/{a,b}/{x,y}.go`, []string{`src/a/x.go`, `src/a/y.go`, `src/b/x.go`, `src/b/y.go`}},
+		{`a{,b}`, []string{`a`, `ab`}},
+		{`{a,b{1,2}}c`, []string{`ac`, `b1c`, `b2c`}},
+		{`log.{1..3}.gz`, []string{`log.1.gz`, `log.2.gz`, `log.3.gz`}},
+		{`{01..03}{a..b}`, []string{`01a`, `01b`, `02a`, `02b`, `03a`, `03b`}},
+		{`{x,{3..1..2}}`, []string{`x`, `3`, `1`}},
+		{`\{a,b\}\*`, []string{`{a,b}*`}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v, err := ExpandBraces(test.v)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, v)
+			}
+			var seq []string
+			for s, err := range ExpandBracesSeq(test.v) {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				seq = append(seq, s)
+			}
+			if !reflect.DeepEqual(seq, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, seq)
+			}
+		})
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	for i, test := range []struct {
+		v     string
+		limit int
+		err   error
+	}{
+		{`{a,b}*`, 0, nil},
+		{`[a-c]`, 0, nil},
+		{`[a-`, 0, nil},
+		{`{1..10}{1..10}`, 99, ErrExpandLimit},
+		{`{1..10}{1..10}`, 100, nil},
+		{`{1..1000000}{1..1000000}{1..1000000}{1..1000000}`, 1000, ErrExpandLimit},
+		{`{a,b,c}{a,b,c}`, 8, ErrExpandLimit},
+		{`{-9223372036854775807..9223372036854775807}`, 0, ErrExpandLimit},
+		{`{-9223372036854775807..9223372036854775807}`, 1000, ErrExpandLimit},
+		{`{-9223372036854775807..9223372036854775807..2}`, 0, ErrExpandLimit},
+		{`{0..4294967296}{0..4294967296}{0..4294967296}`, -1, ErrExpandLimit},
+		{`{{0..4611686018427387904},{0..4611686018427387904}}`, 0, ErrExpandLimit},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v, err := ExpandBracesLimit(test.v, test.limit)
+			switch {
+			case err == nil:
+				if test.err != nil || test.limit == 0 {
+					t.Fatalf("expected error, got: %q", v)
+				}
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got: %v", test.err, err)
+			}
+		})
+	}
+	n := 0
+	for s, err := range ExpandBracesSeq(`{1..1000000}{1..1000000}`) {
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if n++; n == 3 {
+			if s != "13" {
+				t.Errorf("expected %q, got: %q", "13", s)
+			}
+			break
+		}
+	}
+}
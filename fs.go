@@ -0,0 +1,57 @@
+package glob
+
+import (
+	"io/fs"
+	"iter"
+	"strings"
+)
+
+// Files compiles pattern using `/` as the separator, and returns a sequence of
+// the paths in fsys that match it. See [Glob.FS].
+func Files(fsys fs.FS, pattern string) iter.Seq2[string, error] {
+	g, err := Compile(pattern, '/')
+	if err != nil {
+		return func(yield func(string, error) bool) {
+			yield("", err)
+		}
+	}
+	return g.FS(fsys, ".")
+}
+
+// FS returns a sequence of the paths of the files and directories in fsys
+// under root that match the glob. Paths are matched relative to root, and are
+// yielded in lexical order as full paths that can be passed to fsys.Open.
+//
+// Directories that cannot contain a match (see [Glob.CanMatchPrefix]) are not
+// walked. Errors encountered while walking fsys are yielded with the path of
+// the offending directory, after which the walk continues. The glob should
+// normally be compiled with `/` as a separator, as that is the separator
+// used by [fs.FS] paths.
+func (g *Glob) FS(fsys fs.FS, root string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		prefix := root + "/"
+		if root == "." {
+			prefix = ""
+		}
+		_ = fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+			rel := strings.TrimPrefix(name, prefix)
+			switch {
+			case err != nil:
+				if !yield(name, err) {
+					return fs.SkipAll
+				}
+				return nil
+			case name == root:
+				return nil
+			case g.Match(rel):
+				if !yield(name, nil) {
+					return fs.SkipAll
+				}
+			}
+			if d.IsDir() && !g.CanMatchPrefix(rel+"/") {
+				return fs.SkipDir
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":                      {},
+		"main.go":                     {},
+		"src/api/main.go":             {},
+		"src/api/main_test.go":        {},
+		"src/api/v1/main.go":          {},
+		"src/cmd/main.go":             {},
+		"src/testdata/a.json":         {},
+		"src/pkg/testdata/b.json":     {},
+		"src/pkg/testdata/sub/c.json": {},
+		"docs/README.md":              {},
+	}
+	for i, test := range []struct {
+		pattern string
+		exp     []string
+	}{
+		{`*.go`, []string{"main.go"}},
+		{`src/*/main.go`, []string{"src/api/main.go", "src/cmd/main.go"}},
+		{`src/**.go`, []string{"src/api/main.go", "src/api/main_test.go", "src/api/v1/main.go", "src/cmd/main.go"}},
+		{`src/**/testdata/*.json`, []string{"src/pkg/testdata/b.json"}},
+		{`{docs,src}`, []string{"docs", "src"}},
+		{`nothing`, nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var v []string
+			for name, err := range Files(fsys, test.pattern) {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				v = append(v, name)
+			}
+			if !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, v)
+			}
+		})
+	}
+}
+
+func TestFilesPrune(t *testing.T) {
+	fsys := &readDirFS{
+		FS: fstest.MapFS{
+			"src/a/testdata/a.json": {},
+			"src/b/c/d.json":        {},
+			"docs/a/b.md":           {},
+			"vendor/a/b/c.go":       {},
+		},
+	}
+	var v []string
+	for name, err := range Files(fsys, `src/**/testdata/*.json`) {
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		v = append(v, name)
+	}
+	if exp := []string{"src/a/testdata/a.json"}; !reflect.DeepEqual(v, exp) {
+		t.Errorf("expected %q, got: %q", exp, v)
+	}
+	exp := []string{".", "src", "src/a", "src/a/testdata", "src/b", "src/b/c"}
+	if !reflect.DeepEqual(fsys.dirs, exp) {
+		t.Errorf("expected %q, got: %q", exp, fsys.dirs)
+	}
+}
+
+// readDirFS records the directories read.
+type readDirFS struct {
+	fs.FS
+	dirs []string
+}
+
+func (fsys *readDirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fsys.dirs = append(fsys.dirs, name)
+	return fs.ReadDir(fsys.FS, name)
+}
+
+func TestGlobFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/b/c.txt": {},
+		"a/b/d.txt": {},
+		"a/e.txt":   {},
+	}
+	var v []string
+	for name, err := range Must(`b/*.txt`, '/').FS(fsys, "a") {
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		v = append(v, name)
+		break
+	}
+	if exp := []string{"a/b/c.txt"}; !reflect.DeepEqual(v, exp) {
+		t.Errorf("expected %q, got: %q", exp, v)
+	}
+	for name, err := range Must(`*`, '/').FS(fsys, "missing") {
+		if name != "missing" || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected not exist error, got: %q %v", name, err)
+		}
+	}
+	for _, err := range Files(fsys, `[`) {
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+}
+
+func TestGlobFSLeadingDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/b/c.txt":   {},
+		"foo/bar/baz": {},
+		"foo/qux":     {},
+	}
+	for i, test := range []struct {
+		pattern string
+		exp     []string
+	}{
+		{`*`, []string{"a", "a/b", "a/b/c.txt", "foo", "foo/bar", "foo/bar/baz", "foo/qux"}},
+		{`foo/*`, []string{"foo/bar", "foo/bar/baz", "foo/qux"}},
+		{`a/b`, []string{"a/b", "a/b/c.txt"}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.pattern, WithSeparators('/'), WithLeadingDir())
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var v []string
+			for name, err := range g.FS(fsys, ".") {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				v = append(v, name)
+			}
+			if !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, v)
+			}
+		})
+	}
+}
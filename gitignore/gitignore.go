@@ -0,0 +1,267 @@
+// Package gitignore provides gitignore-compatible path matching.
+package gitignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/kenshaw/glob/syntax"
+)
+
+// FileName is the name of ignore files.
+const FileName = ".gitignore"
+
+// Rule is a single rule from an ignore file.
+type Rule struct {
+	// Pattern is the pattern of the rule, as written in the ignore file.
+	Pattern string
+	// Negate is true when the rule re-includes paths (`!`).
+	Negate bool
+	// DirOnly is true when the rule only matches directories (trailing `/`).
+	DirOnly bool
+	// Anchored is true when the rule is matched relative to the directory of
+	// the ignore file, rather than at any level below it.
+	Anchored bool
+	m        syntax.Matcher
+}
+
+// ParseRule parses a single line of an ignore file. Returns false when the
+// line is blank or a comment. As with git, a rule with an invalid pattern
+// (such as `[abc`) is kept, but never matches.
+func ParseRule(line string) (Rule, bool, error) {
+	line = strings.TrimSuffix(line, "\r")
+	pattern := trimTrailingSpace(line)
+	if pattern == "" || pattern[0] == '#' {
+		return Rule{}, false, nil
+	}
+	r := Rule{Pattern: pattern}
+	if pattern[0] == '!' {
+		r.Negate, pattern = true, pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") && !strings.HasSuffix(pattern, `\/`) {
+		r.DirOnly, pattern = true, strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return Rule{}, false, nil
+	}
+	if strings.Contains(pattern, "/") {
+		r.Anchored, pattern = true, strings.TrimPrefix(pattern, "/")
+	}
+	if n := len(pattern) - len(strings.TrimRight(pattern, `\`)); n%2 == 1 {
+		// a trailing backslash is invalid, and the rule never matches
+		return r, true, nil
+	}
+	s := translate(pattern)
+	if !r.Anchored {
+		s = "{,**/}" + s
+	}
+	tree, err := syntax.Parse(syntax.NewLexer(s))
+	if err != nil {
+		// as with git, a pattern that does not parse never matches
+		return r, true, nil
+	}
+	if m, err := tree.Match([]rune{'/'}); err == nil {
+		r.m = m
+	}
+	return r, true, nil
+}
+
+// Parse parses the rules of an ignore file.
+func Parse(rd io.Reader) ([]Rule, error) {
+	var rules []Rule
+	s := bufio.NewScanner(rd)
+	for n := 1; s.Scan(); n++ {
+		r, ok, err := ParseRule(s.Text())
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		case ok:
+			rules = append(rules, r)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Match reports whether the rule matches the path. The path is `/` separated
+// and relative to the directory of the ignore file containing the rule.
+func (r Rule) Match(name string, isDir bool) bool {
+	return (isDir || !r.DirOnly) && r.m != nil && r.m.Match(name)
+}
+
+// Matcher matches paths against the rules of a tree of ignore files.
+type Matcher struct {
+	dirs map[string][]Rule
+}
+
+// New creates a new, empty matcher.
+func New() *Matcher {
+	return &Matcher{
+		dirs: make(map[string][]Rule),
+	}
+}
+
+// Add adds rules for the directory dir, relative to the root of the matcher
+// (use "." for the root). Rules added later take precedence over rules added
+// earlier for the same directory.
+func (m *Matcher) Add(dir string, rules ...Rule) {
+	dir = path.Clean(dir)
+	m.dirs[dir] = append(m.dirs[dir], rules...)
+}
+
+// AddFile parses and adds the rules of an ignore file in the directory dir.
+func (m *Matcher) AddFile(dir string, rd io.Reader) error {
+	rules, err := Parse(rd)
+	if err != nil {
+		return err
+	}
+	m.Add(dir, rules...)
+	return nil
+}
+
+// FromFS creates a matcher from the ignore files in fsys under root. Ignore
+// files in directories that are themselves ignored are not read, matching
+// git's behavior.
+func FromFS(fsys fs.FS, root string) (*Matcher, error) {
+	m := New()
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.IsDir():
+			return nil
+		}
+		rel := relPath(root, name)
+		if rel != "." && (d.Name() == ".git" || m.Ignored(rel, true)) {
+			return fs.SkipDir
+		}
+		f, err := fsys.Open(path.Join(name, FileName))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return err
+		}
+		defer f.Close()
+		if err := m.AddFile(rel, f); err != nil {
+			return fmt.Errorf("%s: %w", path.Join(name, FileName), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Ignored reports whether the path is ignored. The path is `/` separated and
+// relative to the root of the matcher. As with git, a path is ignored when any
+// of its parent directories is ignored, and otherwise the last matching rule
+// wins, with rules in deeper directories taking precedence.
+func (m *Matcher) Ignored(name string, isDir bool) bool {
+	name = path.Clean(name)
+	for i := range len(name) {
+		if name[i] == '/' && m.match(name[:i], true) {
+			return true
+		}
+	}
+	return m.match(name, isDir)
+}
+
+// match matches the path against the rules of the ignore files in its parent
+// directories.
+func (m *Matcher) match(name string, isDir bool) bool {
+	ignored := m.matchDir(".", name, isDir, false)
+	for i := range len(name) {
+		if name[i] == '/' {
+			ignored = m.matchDir(name[:i], name[i+1:], isDir, ignored)
+		}
+	}
+	return ignored
+}
+
+// matchDir matches the path, relative to dir, against the rules of dir.
+func (m *Matcher) matchDir(dir, name string, isDir, ignored bool) bool {
+	for _, r := range m.dirs[dir] {
+		if r.Match(name, isDir) {
+			ignored = !r.Negate
+		}
+	}
+	return ignored
+}
+
+// relPath returns name relative to root.
+func relPath(root, name string) string {
+	switch {
+	case root == ".":
+		return name
+	case name == root:
+		return "."
+	}
+	return strings.TrimPrefix(name, root+"/")
+}
+
+// trimTrailingSpace trims unescaped trailing spaces.
+func trimTrailingSpace(s string) string {
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// translate translates a gitignore pattern to the glob syntax.
+func translate(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			i++
+			if strings.IndexByte("*?[]\\{}", pattern[i]) != -1 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(pattern[i])
+		case '{', '}':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '[':
+			b.WriteByte(c)
+			if i+1 < len(pattern) && (pattern[i+1] == '^' || pattern[i+1] == '!') {
+				b.WriteByte('!')
+				i++
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				// a leading `]` is part of the set
+				b.WriteString(`\]`)
+				i++
+			}
+		case '*':
+			j := i
+			for j < len(pattern) && pattern[j] == '*' {
+				j++
+			}
+			switch {
+			case j-i < 2, i > 0 && pattern[i-1] != '/', j < len(pattern) && pattern[j] != '/':
+				// other consecutive asterisks are regular asterisks
+				b.WriteByte('*')
+			case j == len(pattern):
+				// trailing `**` matches everything inside
+				b.WriteString("**")
+			default:
+				// leading `**/` and `/**/` match zero or more directories
+				b.WriteString("{,**/}")
+				j++
+			}
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
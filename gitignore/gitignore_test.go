@@ -0,0 +1,184 @@
+package gitignore
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseRule(t *testing.T) {
+	for i, test := range []struct {
+		line     string
+		ok       bool
+		negate   bool
+		dirOnly  bool
+		anchored bool
+		name     string
+		match    bool
+	}{
+		{"", false, false, false, false, "", false},
+		{"   ", false, false, false, false, "", false},
+		{"# comment", false, false, false, false, "# comment", false},
+		{`\#file`, true, false, false, false, "#file", true},
+		{"*.log", true, false, false, false, "a/b.log", true},
+		{"!keep.log", true, true, false, false, "keep.log", true},
+		{`\!file`, true, false, false, false, "!file", true},
+		{"build/", true, false, true, false, "a/build", true},
+		{"/build", true, false, false, true, "a/build", false},
+		{"doc/frotz/", true, false, true, true, "doc/frotz", true},
+		{"**/foo", true, false, false, true, "a/foo", true},
+		{"/", false, false, false, false, "", false},
+		{"[abc", true, false, false, false, "[abc", false},
+		{"a/[", true, false, false, true, "a/[", false},
+		{"[]a]", true, false, false, false, "]", true},
+		{"[]a]", true, false, false, false, "b", false},
+		{"[!]a]", true, false, false, false, "b", true},
+		{"[^]a]", true, false, false, false, "]", false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r, ok, err := ParseRule(test.line)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if ok != test.ok {
+				t.Fatalf("expected %t, got: %t", test.ok, ok)
+			}
+			if r.Negate != test.negate || r.DirOnly != test.dirOnly || r.Anchored != test.anchored {
+				t.Errorf("expected negate=%t dirOnly=%t anchored=%t, got: %+v", test.negate, test.dirOnly, test.anchored, r)
+			}
+			if b := r.Match(test.name, true); b != test.match {
+				t.Errorf("%q: expected %t, got: %t", test.name, test.match, b)
+			}
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	const ignore = `
+# comment
+*.log
+!important.log
+/root.txt
+build/
+doc/*.txt
+**/deep
+a/**/z
+out/**
+foo**bar
+\#hash
+trailing\
+space\` + " " + `
+{brace}
+[^x]y
+`
+	m := New()
+	if err := m.AddFile(".", strings.NewReader(ignore)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := m.AddFile("sub", strings.NewReader("!*.log\n/local\n")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []struct {
+		path  string
+		isDir bool
+		exp   bool
+	}{
+		{"x.log", false, true},
+		{"a/b/x.log", false, true},
+		{"important.log", false, false},
+		{"a/important.log", false, false},
+		{"root.txt", false, true},
+		{"a/root.txt", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"a/build", true, true},
+		{"a/build/file.go", false, true},
+		{"doc/a.txt", false, true},
+		{"doc/a/b.txt", false, false},
+		{"a/doc/a.txt", false, false},
+		{"deep", false, true},
+		{"a/b/deep", true, true},
+		{"a/b/deep/file", false, true},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"b/a/z", false, false},
+		{"out", true, false},
+		{"out/a/b", false, true},
+		{"fooxbar", false, true},
+		{"foo/bar", false, false},
+		{"#hash", false, true},
+		{"trailing", false, false},
+		{`trailing\`, false, false},
+		{"space ", false, true},
+		{"space", false, false},
+		{"{brace}", false, true},
+		{"ay", false, true},
+		{"xy", false, false},
+		{"sub/x.log", false, false},
+		{"sub/local", false, true},
+		{"local", false, false},
+		{"main.go", false, false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if b := m.Ignored(test.path, test.isDir); b != test.exp {
+				t.Errorf("%q: expected %t, got: %t", test.path, test.exp, b)
+			}
+		})
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":          {Data: []byte("vendor/\n*.tmp\n")},
+		"a/.gitignore":        {Data: []byte("!keep.tmp\nlocal/\n")},
+		"a/local/.gitignore":  {Data: []byte("!*\n")},
+		"vendor/.gitignore":   {Data: []byte("!*\n")},
+		"a/keep.tmp":          {},
+		"a/x.tmp":             {},
+		"a/local/file.go":     {},
+		"vendor/lib/file.go":  {},
+		"main.go":             {},
+		"b/c/.gitignore":      {Data: []byte("/d\n")},
+		"b/c/d/file.go":       {},
+		"b/d/file.go":         {},
+		"bad/.gitignore":      {Data: []byte("[\n*.txt\n")},
+		"bad/nested/file.txt": {},
+		"bad/nested/[":        {},
+		"c/.gitignore":        {Data: []byte("[]a]\n")},
+		"c/]":                 {},
+		"c/b":                 {},
+	}
+	m, err := FromFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []struct {
+		path  string
+		isDir bool
+		exp   bool
+	}{
+		{"main.go", false, false},
+		{"x.tmp", false, true},
+		{"a/x.tmp", false, true},
+		{"a/keep.tmp", false, false},
+		{"a/local", true, true},
+		{"a/local/file.go", false, true},
+		{"vendor/lib/file.go", false, true},
+		{"b/c/d/file.go", false, true},
+		{"b/d/file.go", false, false},
+		{"bad/nested/file.txt", false, true},
+		{"bad/nested/[", false, false},
+		{"c/]", false, true},
+		{"c/b", false, false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if b := m.Ignored(test.path, test.isDir); b != test.exp {
+				t.Errorf("%q: expected %t, got: %t", test.path, test.exp, b)
+			}
+		})
+	}
+	if _, ok := m.dirs["vendor"]; ok {
+		t.Errorf("expected ignore file in ignored directory to not be read")
+	}
+}
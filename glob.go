@@ -2,6 +2,12 @@
 package glob
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/kenshaw/glob/syntax"
 )
 
@@ -9,11 +15,19 @@ import (
 type Glob struct {
 	syntax.Matcher
 	pattern string
+	opts    Options
+	tree    *syntax.Node
+	bt      syntax.BacktrackMatcher
 }
 
-// New creates a new, empty glob.
-func New() *Glob {
-	return new(Glob)
+// New creates a new, empty glob using the options. The options are used when
+// the glob is later unmarshaled.
+func New(opts ...Option) *Glob {
+	g := new(Glob)
+	for _, o := range opts {
+		o(&g.opts)
+	}
+	return g
 }
 
 // Compile creates a [Glob] for given pattern and strings (if any present after
@@ -27,56 +41,309 @@ func New() *Glob {
 //	    `**`        matches any sequence of characters
 //	    `?`         matches any single non-separator character
 //	    `[` [ `!` ] { character-range } `]`
-//	                character class (must be non-empty)
+//	                character class (must be non-empty), matching any of
+//	                the character ranges
 //	    `{` pattern-list `}`
 //	                pattern alternatives
+//	    `{` x `..` y [ `..` incr ] `}`
+//	                brace sequence, matching the integers (or single
+//	                characters) from x to y in steps of incr, where integers
+//	                with a leading zero are matched zero-padded
 //	    c           matches character c (c != `*`, `**`, `?`, `\`, `[`, `{`, `}`)
 //	    `\` c       matches character c
 //
 //	character-range:
-//	    c           matches character c (c != `\\`, `-`, `]`)
+//	    c           matches character c (c != `\\`, `]`, and c != `-`
+//	                unless first or last)
 //	    `\` c       matches character c
-//	    lo `-` hi   matches character c for lo <= c <= hi
+//	    lo `-` hi   matches character c for lo <= c <= hi (lo and hi may
+//	                be escaped)
+//	    `[:` class `:]`
+//	                matches character c in the POSIX character class (one of
+//	                alnum, alpha, blank, cntrl, digit, graph, lower, print,
+//	                punct, space, upper or xdigit), using Unicode categories
+//	    `\p{` name `}`
+//	                matches character c in the Unicode category, script or
+//	                property (such as L, Lu, Han or White_Space), with `\pN`
+//	                for single letter names
+//	    `\P{` name `}`
+//	                matches character c not in the Unicode category, script
+//	                or property
 //
 //	pattern-list:
 //	    pattern { `,` pattern }
 //	                comma-separated (without spaces) patterns
+//
+// Syntax errors in the pattern are returned as a [*syntax.Error], whose Caret
+// method marks the position of the error in the pattern.
+//
+// See [CompileWithOptions] for compiling patterns with other options.
 func Compile(pattern string, separators ...rune) (*Glob, error) {
-	tree, err := syntax.Parse(syntax.NewLexer(pattern))
-	if err != nil {
-		return nil, err
-	}
-	m, err := tree.Match(separators)
-	if err != nil {
-		return nil, err
-	}
-	return &Glob{Matcher: m, pattern: pattern}, nil
+	return Options{Separators: separators}.Compile(pattern)
 }
 
-// UnmarshalText satisfies the [encoding.TextUnarshaler] interface.
-func (g *Glob) UnmarshalText(buf []byte) error {
-	tree, err := syntax.Parse(syntax.NewLexer(string(buf)))
+// compile compiles the pattern with the options into g.
+func (g *Glob) compile(pattern string, opts Options) error {
+	if err := opts.validate(pattern); err != nil {
+		return err
+	}
+	tree, err := opts.parse(pattern)
 	if err != nil {
 		return err
 	}
-	m, err := tree.Match(nil)
+	flags, sep := opts.flags(), opts.separators()
+	m, err := tree.MatchFlags(sep, flags)
 	if err != nil {
 		return err
 	}
-	g.Matcher, g.pattern = m, string(buf)
+	g.Matcher, g.pattern, g.opts, g.tree = m, pattern, opts, tree
+	g.bt = syntax.NewBacktrack(tree, sep, flags)
 	return nil
 }
 
-// MarshalText satisfies the [encoding.TextMarhsaler] interface.
+// UnmarshalText satisfies the [encoding.TextUnarshaler] interface. The
+// pattern is compiled using the options of g, or [DefaultOptions] when g was
+// not created with any options.
+func (g *Glob) UnmarshalText(buf []byte) error {
+	opts := g.opts
+	if opts.isZero() {
+		opts = DefaultOptions
+	}
+	return g.compile(string(buf), opts)
+}
+
+// MarshalText satisfies the [encoding.TextMarhsaler] interface. Only the
+// pattern is marshaled, so the options are not kept when round-tripping
+// through text: the pattern is unmarshaled with the options of the receiving
+// glob, or [DefaultOptions]. See [Glob.MarshalJSON] for a form that keeps the
+// options.
 func (g *Glob) MarshalText() ([]byte, error) {
 	return []byte(g.pattern), nil
 }
 
+// UnmarshalJSON satisfies the [json.Unmarshaler] interface. Accepts either a
+// string, which is unmarshaled as with [Glob.UnmarshalText], or an object
+// as produced by [Glob.MarshalJSON]. As is conventional, `null` is a no-op.
+func (g *Glob) UnmarshalJSON(buf []byte) error {
+	if string(buf) == "null" {
+		return nil
+	}
+	var pattern string
+	if err := json.Unmarshal(buf, &pattern); err == nil {
+		return g.UnmarshalText([]byte(pattern))
+	}
+	var v globJSON
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+	return g.compile(v.Pattern, v.options())
+}
+
+// MarshalJSON satisfies the [json.Marshaler] interface. A glob compiled with
+// the zero options is marshaled as a string, otherwise as an object
+// containing the pattern and the options. The object form is always used
+// when [DefaultOptions] is set, as the string would be unmarshaled with
+// those options.
+func (g *Glob) MarshalJSON() ([]byte, error) {
+	if g.opts.isZero() && DefaultOptions.isZero() {
+		return json.Marshal(g.pattern)
+	}
+	return json.Marshal(newGlobJSON(g.pattern, g.opts))
+}
+
 // String satisfies the [fmt.Stringer] interface.
 func (g *Glob) String() string {
 	return g.pattern
 }
 
+// Regexp returns a regular expression equivalent to the glob. Returns an
+// error when the pattern has no RE2 equivalent, or the glob was compiled with
+// the options [Options.Pathname], [Options.Period], [Options.LeadingDir] or
+// [Options.NoDotGlob]. See [syntax.ToRegexp].
+func (g *Glob) Regexp() (*regexp.Regexp, error) {
+	if g.opts.Pathname || g.opts.Period || g.opts.LeadingDir || g.opts.NoDotGlob {
+		return nil, fmt.Errorf("pattern %q: cannot translate options to a regexp", g.pattern)
+	}
+	s, err := syntax.ToRegexp(g.tree, g.opts.separators())
+	if err != nil {
+		return nil, fmt.Errorf("pattern %q: %w", g.pattern, err)
+	}
+	if g.opts.CaseFold {
+		s = "(?i)" + s
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("pattern %q: %w", g.pattern, err)
+	}
+	return re, nil
+}
+
+// Options returns the options the glob was compiled with.
+func (g *Glob) Options() Options {
+	return g.opts
+}
+
+// FindSubmatch returns the substrings of s matched by each wildcard term
+// (`*`, `**`, `?`, `[...]` and `{...}`) of the pattern, in order. Wildcards
+// nested inside a `{...}` term are reported as part of the enclosing term.
+// Returns nil when s does not match.
+//
+// Unlike [regexp.Regexp.FindSubmatch], the pattern is anchored to the whole of
+// s, and the result does not include the full match.
+func (g *Glob) FindSubmatch(s string) []string {
+	v := g.bt.Submatch(s)
+	if v == nil {
+		return nil
+	}
+	sub := make([]string, len(v)/2)
+	for i := range sub {
+		sub[i] = s[v[2*i]:v[2*i+1]]
+	}
+	return sub
+}
+
+// FindSubmatchIndex returns a pair of byte offsets into s identifying the
+// span matched by each wildcard term of the pattern, in the same order as
+// [Glob.FindSubmatch]. Returns nil when s does not match.
+func (g *Glob) FindSubmatchIndex(s string) []int {
+	return g.bt.Submatch(s)
+}
+
+// NumTerms returns the number of wildcard terms in the pattern, which is the
+// number of submatches reported by [Glob.FindSubmatch].
+func (g *Glob) NumTerms() int {
+	return g.bt.NumTerms()
+}
+
+// CanMatchPrefix reports whether prefix could be extended to a string that
+// matches the pattern. When false, no string starting with prefix can match,
+// which allows a walker to prune a directory by checking its path followed by
+// a separator.
+func (g *Glob) CanMatchPrefix(prefix string) bool {
+	return g.bt.MatchPrefix(prefix)
+}
+
+// Find returns the text of the leftmost match of the pattern in s, or the
+// empty string if there is no match. Unlike [Glob.Match], the match may start
+// and end anywhere in s. As with [regexp.Regexp], of the matches starting at
+// the leftmost position, the first one in backtracking order is returned,
+// meaning wildcards match as much of s as they can.
+func (g *Glob) Find(s string) string {
+	if v := g.bt.Find(s, 0); v != nil {
+		return s[v[0]:v[1]]
+	}
+	return ""
+}
+
+// FindIndex returns a pair of byte offsets into s identifying the leftmost
+// match of the pattern in s (see [Glob.Find]). Returns nil when there is no
+// match.
+func (g *Glob) FindIndex(s string) []int {
+	return g.bt.Find(s, 0)
+}
+
+// FindAll returns the text of successive non-overlapping matches of the
+// pattern in s (see [Glob.Find]). If n >= 0, at most n matches are returned.
+// As with [regexp.Regexp.FindAllString], empty matches abutting a preceding
+// match are ignored. Returns nil when there is no match.
+func (g *Glob) FindAll(s string, n int) []string {
+	var v []string
+	for _, i := range g.FindAllIndex(s, n) {
+		v = append(v, s[i[0]:i[1]])
+	}
+	return v
+}
+
+// FindAllIndex returns pairs of byte offsets into s identifying successive
+// non-overlapping matches of the pattern in s (see [Glob.FindAll]). Returns
+// nil when there is no match.
+func (g *Glob) FindAllIndex(s string, n int) [][]int {
+	return g.bt.FindAll(s, n)
+}
+
+// Expand matches s against the pattern and returns template with references to
+// submatches replaced by the span of s matched by the corresponding wildcard
+// term. Returns false when s does not match.
+//
+// In the template, a reference is written `$n`, `${n}` or `#n` (as in mmv),
+// where n is the 1-based index of the wildcard term as reported by
+// [Glob.FindSubmatch], and `$0` refers to the whole of s. References to terms
+// that do not exist are replaced with the empty string. Use `$$` for a
+// literal `$`. For example, for the pattern `*.tar.gz`, the template `$1.tgz`
+// expands `logs.tar.gz` to `logs.tgz`.
+func (g *Glob) Expand(template, s string) (string, bool) {
+	sub := g.bt.Submatch(s)
+	if sub == nil {
+		return "", false
+	}
+	return string(expand(nil, template, s, sub)), true
+}
+
+// Replace returns the expansion of template for s (see [Glob.Expand]) when s
+// matches the pattern, otherwise returns s unchanged.
+func (g *Glob) Replace(s, template string) string {
+	if v, ok := g.Expand(template, s); ok {
+		return v
+	}
+	return s
+}
+
+// expand appends template to buf, replacing submatch references with the
+// corresponding span of s.
+func expand(buf []byte, template, s string, sub []int) []byte {
+	for len(template) > 0 {
+		i := strings.IndexAny(template, "$#")
+		if i == -1 {
+			break
+		}
+		buf, template = append(buf, template[:i]...), template[i:]
+		if template[0] == '$' && len(template) > 1 && template[1] == '$' {
+			buf, template = append(buf, '$'), template[2:]
+			continue
+		}
+		n, rest, ok := extractRef(template)
+		if !ok {
+			buf, template = append(buf, template[0]), template[1:]
+			continue
+		}
+		switch {
+		case n == 0:
+			buf = append(buf, s...)
+		case 2*n <= len(sub):
+			buf = append(buf, s[sub[2*n-2]:sub[2*n-1]]...)
+		}
+		template = rest
+	}
+	return append(buf, template...)
+}
+
+// extractRef extracts a leading `$n`, `${n}` or `#n` reference from s.
+func extractRef(s string) (int, string, bool) {
+	brace := s[0] == '$' && len(s) > 1 && s[1] == '{'
+	i := 1
+	if brace {
+		i++
+	}
+	j := i
+	for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+		j++
+	}
+	if j == i {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(s[i:j])
+	if err != nil {
+		return 0, "", false
+	}
+	if brace {
+		if j >= len(s) || s[j] != '}' {
+			return 0, "", false
+		}
+		j++
+	}
+	return n, s[j:], true
+}
+
 // Must is the same as Compile, except that if Compile returns error, this will
 // panic
 func Must(pattern string, separators ...rune) *Glob {
@@ -1,10 +1,29 @@
 package glob
 
 import (
+	"errors"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/kenshaw/glob/syntax"
 )
 
+func TestCompileError(t *testing.T) {
+	_, err := Compile("src/[z-a].go", '/')
+	var e *syntax.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *syntax.Error, got: %v", err)
+	}
+	if e.Pattern != "src/[z-a].go" || e.Offset != 5 || e.Code != syntax.ErrInvalidRange {
+		t.Errorf("expected invalid range at offset 5, got: %q at offset %d", e.Code, e.Offset)
+	}
+	if exp := "src/[z-a].go\n     ^"; e.Caret() != exp {
+		t.Errorf("expected %q, got: %q", exp, e.Caret())
+	}
+}
+
 func TestCompile(t *testing.T) {
 	for i, test := range []struct {
 		s   string
@@ -22,6 +41,21 @@ func TestCompile(t *testing.T) {
 			``,
 			0, false,
 		},
+		{
+			``,
+			`?`,
+			0, false,
+		},
+		{
+			``,
+			`[!a]`,
+			0, false,
+		},
+		{
+			``,
+			`[!a-c]`,
+			0, false,
+		},
 		{
 			`åä`,
 			`*ä`,
@@ -322,11 +356,381 @@ func TestCompile(t *testing.T) {
 			`*//{,*.}example.com`,
 			0, false,
 		},
+		{
+			`a/b/x.log`,
+			`{,**/}*.log`,
+			'/', true,
+		},
 		{
 			`abc`,
 			`{a*,b}c`,
 			'.', true,
 		},
+		{
+			`file1.txt`,
+			`file[[:digit:]].txt`,
+			0, true,
+		},
+		{
+			`file١.txt`,
+			`file[[:digit:]].txt`,
+			0, true,
+		},
+		{
+			`fileA.txt`,
+			`file[[:digit:]].txt`,
+			0, false,
+		},
+		{
+			`fileA.txt`,
+			`file[![:digit:]].txt`,
+			0, true,
+		},
+		{
+			`Ärger`,
+			`[[:upper:]]*`,
+			0, true,
+		},
+		{
+			`ärger`,
+			`[[:upper:]]*`,
+			0, false,
+		},
+		{
+			`ärger`,
+			`[[:lower:]][[:alpha:]]*`,
+			0, true,
+		},
+		{
+			`a b`,
+			`a[[:space:]]b`,
+			0, true,
+		},
+		{
+			`a　b`,
+			`a[[:space:]]b`,
+			0, true,
+		},
+		{
+			`a!b`,
+			`a[[:punct:]]b`,
+			0, true,
+		},
+		{
+			`a$b`,
+			`a[[:punct:]]b`,
+			0, true,
+		},
+		{
+			`a_b`,
+			`a[[:alnum:]]b`,
+			0, false,
+		},
+		{
+			`0xBEEF`,
+			`0x[[:xdigit:]][[:xdigit:]]*`,
+			0, true,
+		},
+		{
+			`0xBEEG`,
+			`0x*[![:xdigit:]]`,
+			0, true,
+		},
+		{
+			`0xBEEF`,
+			`0x*[![:xdigit:]]`,
+			0, false,
+		},
+		{
+			`abc`,
+			`*[[:alpha:]]`,
+			0, true,
+		},
+		{
+			`Go_1`,
+			`[a-zA-Z0-9_][a-z]_[0-9]`,
+			0, true,
+		},
+		{
+			`g-_1`,
+			`[a-zA-Z0-9_][a-z]_[0-9]`,
+			0, false,
+		},
+		{
+			`x-y`,
+			`x[a-]y`,
+			0, true,
+		},
+		{
+			`x-y`,
+			`x[-a]y`,
+			0, true,
+		},
+		{
+			`x]y`,
+			`x[\]a]y`,
+			0, true,
+		},
+		{
+			`x-y`,
+			`x[a\-z]y`,
+			0, true,
+		},
+		{
+			`xby`,
+			`x[a\-z]y`,
+			0, false,
+		},
+		{
+			`x.y`,
+			`x[!a-zA-Z0-9]y`,
+			0, true,
+		},
+		{
+			`xQy`,
+			`x[!a-zA-Z0-9]y`,
+			0, false,
+		},
+		{
+			`é.txt`,
+			`[a-zà-ÿ]*.txt`,
+			0, true,
+		},
+		{
+			`Ω.txt`,
+			`[a-zà-ÿ]*.txt`,
+			0, false,
+		},
+		{
+			`_1`,
+			`[[:alpha:]_][[:digit:]a-f]`,
+			0, true,
+		},
+		{
+			`x1`,
+			`[![:alpha:]_][[:digit:]a-f]`,
+			0, false,
+		},
+		{
+			`日本`,
+			`[一-龥ぁ-ん]*`,
+			0, true,
+		},
+		{
+			`log.7.gz`,
+			`log.{1..30}.gz`,
+			0, true,
+		},
+		{
+			`log.30.gz`,
+			`log.{1..30}.gz`,
+			0, true,
+		},
+		{
+			`log.31.gz`,
+			`log.{1..30}.gz`,
+			0, false,
+		},
+		{
+			`log.07.gz`,
+			`log.{1..30}.gz`,
+			0, false,
+		},
+		{
+			`log.0.gz`,
+			`log.{1..30}.gz`,
+			0, false,
+		},
+		{
+			`2024/03/x`,
+			`2024/{01..12}/*`,
+			0, true,
+		},
+		{
+			`2024/3/x`,
+			`2024/{01..12}/*`,
+			0, false,
+		},
+		{
+			`2024/13/x`,
+			`2024/{01..12}/*`,
+			0, false,
+		},
+		{
+			`c.txt`,
+			`{a..f}.txt`,
+			0, true,
+		},
+		{
+			`g.txt`,
+			`{a..f}.txt`,
+			0, false,
+		},
+		{
+			`-3`,
+			`{-5..5}`,
+			0, true,
+		},
+		{
+			`6`,
+			`{-5..5}`,
+			0, false,
+		},
+		{
+			`-03`,
+			`{-05..05}`,
+			0, true,
+		},
+		{
+			`-3`,
+			`{-05..05}`,
+			0, false,
+		},
+		{
+			`7`,
+			`{1..10..3}`,
+			0, true,
+		},
+		{
+			`8`,
+			`{1..10..3}`,
+			0, false,
+		},
+		{
+			`x123y`,
+			`x{100..200}y`,
+			0, true,
+		},
+		{
+			`x1234y`,
+			`x{100..200}y`,
+			0, false,
+		},
+		{
+			`a10b`,
+			`*{9..11}b`,
+			0, true,
+		},
+		{
+			`part-99999`,
+			`part-{0..1000000}`,
+			0, true,
+		},
+		{
+			`x1..a`,
+			`x{1..a}`,
+			0, true,
+		},
+		{
+			`a.b.c`,
+			`{a,{1..3}}.*`,
+			0, true,
+		},
+		{
+			`2.b.c`,
+			`{a,{1..3}}.*`,
+			0, true,
+		},
+		{
+			`漢字.png`,
+			`[\p{Han}]*`,
+			0, true,
+		},
+		{
+			`kanji.png`,
+			`[\p{Han}]*`,
+			0, false,
+		},
+		{
+			`kanji.png`,
+			`[\P{Han}]*`,
+			0, true,
+		},
+		{
+			`αβγ.txt`,
+			`[\p{Greek}][\p{Greek}]*.txt`,
+			0, true,
+		},
+		{
+			`abc.txt`,
+			`[\p{Greek}]*.txt`,
+			0, false,
+		},
+		{
+			`x1`,
+			`x[\p{N}]`,
+			0, true,
+		},
+		{
+			`x٣`,
+			`x[\pN]`,
+			0, true,
+		},
+		{
+			`xa`,
+			`x[\P{N}]`,
+			0, true,
+		},
+		{
+			`x1`,
+			`x[\P{N}]`,
+			0, false,
+		},
+		{
+			`x1`,
+			`x[!\P{N}]`,
+			0, true,
+		},
+		{
+			`Ж`,
+			`[\p{Lu}]`,
+			0, true,
+		},
+		{
+			`ж`,
+			`[\p{Lu}]`,
+			0, false,
+		},
+		{
+			`_`,
+			`[\p{L}_]`,
+			0, true,
+		},
+		{
+			`1`,
+			`[\p{L}_]`,
+			0, false,
+		},
+		{
+			`1`,
+			`[a\P{L}]`,
+			0, true,
+		},
+		{
+			`b`,
+			`[a\P{L}]`,
+			0, false,
+		},
+		{
+			`a`,
+			`[!\p{Han}\p{Hiragana}]`,
+			0, true,
+		},
+		{
+			`ひ`,
+			`[!\p{Han}\p{Hiragana}]`,
+			0, false,
+		},
+		{
+			` `,
+			`[\p{White_Space}]`,
+			0, true,
+		},
+		{
+			`pa`,
+			`[\pa]a`,
+			0, true,
+		},
 		{fixture_all_match, pattern_all, 0, true},
 		{fixture_all_mismatch, pattern_all, 0, false},
 		{fixture_plain_match, pattern_plain, 0, true},
@@ -360,6 +764,9 @@ func TestCompile(t *testing.T) {
 			if b := g1.Match(test.s); b != test.exp {
 				t.Errorf("expected %t, got: %t", test.exp, b)
 			}
+			if b := g1.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
 			if test.sep != 0 {
 				return
 			}
@@ -374,6 +781,225 @@ func TestCompile(t *testing.T) {
 	}
 }
 
+func TestFindSubmatch(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		s   string
+		sep rune
+		exp []string
+	}{
+		{`src/*/main.go`, `src/api/main.go`, '/', []string{"api"}},
+		{`src/*/main.go`, `src/api/v1/main.go`, '/', nil},
+		{`src/**/main.go`, `src/api/v1/main.go`, '/', []string{"api/v1"}},
+		{`*.tar.gz`, `logs.tar.gz`, 0, []string{"logs"}},
+		{`?at`, `cat`, 0, []string{"c"}},
+		{`[a-c][!x]t`, `bat`, 0, []string{"b", "a"}},
+		{`{cat,dog}-*`, `dog-food`, 0, []string{"dog", "food"}},
+		{`{,*.}example.com`, `www.example.com`, 0, []string{"www."}},
+		{`*-*`, `a-b-c`, 0, []string{"a-b", "c"}},
+		{`abc`, `abc`, 0, []string{}},
+		{`abc`, `abd`, 0, nil},
+		{`åä*`, `åäö`, 0, []string{"ö"}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var sep []rune
+			if test.sep != 0 {
+				sep = append(sep, test.sep)
+			}
+			g, err := Compile(test.v, sep...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			sub := g.FindSubmatch(test.s)
+			if !reflect.DeepEqual(sub, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, sub)
+			}
+			if sub != nil && len(sub) != g.NumTerms() {
+				t.Errorf("expected %d terms, got: %d", g.NumTerms(), len(sub))
+			}
+		})
+	}
+}
+
+func TestCanMatchPrefix(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		s   string
+		sep rune
+		exp bool
+	}{
+		{`src/**/testdata/*.json`, ``, '/', true},
+		{`src/**/testdata/*.json`, `sr`, '/', true},
+		{`src/**/testdata/*.json`, `src/`, '/', true},
+		{`src/**/testdata/*.json`, `src/a/b/`, '/', true},
+		{`src/**/testdata/*.json`, `docs/`, '/', false},
+		{`src/*/main.go`, `src/api/`, '/', true},
+		{`src/*/main.go`, `src/api/v1/`, '/', false},
+		{`*.go`, `src/`, '/', false},
+		{`*.go`, `src/`, 0, true},
+		{`{a,b}/[0-9]/*`, `b/1/`, '/', true},
+		{`{a,b}/[0-9]/*`, `b/x/`, '/', false},
+		{`{a,b}/[0-9]/*`, `c/`, '/', false},
+		{`abc`, `abc`, '/', true},
+		{`abc`, `abcd`, '/', false},
+		{`{2020..2024}/{01..12}/*`, `2023/0`, '/', true},
+		{`{2020..2024}/{01..12}/*`, `2023/`, '/', true},
+		{`{2020..2024}/{01..12}/*`, `2025/`, '/', false},
+		{`{2020..2024}/{01..12}/*`, `2023/13/`, '/', false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var sep []rune
+			if test.sep != 0 {
+				sep = append(sep, test.sep)
+			}
+			g, err := Compile(test.v, sep...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := g.CanMatchPrefix(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		s   string
+		sep rune
+		exp []int
+		all [][]int
+	}{
+		{`req-????-*`, `GET req-1234-abc ok`, ' ', []int{4, 16}, [][]int{{4, 16}}},
+		{`req-????-*`, `req-12-x req-1234-ab req-9999-z`, ' ', []int{9, 20}, [][]int{{9, 20}, {21, 31}}},
+		{`req-????-*`, `no requests here`, ' ', nil, nil},
+		{`a*`, `xaayaaz`, 0, []int{1, 7}, [][]int{{1, 7}}},
+		{`a*`, `xaa.aaz`, '.', []int{1, 3}, [][]int{{1, 3}, {4, 7}}},
+		{`a?`, `abacad`, 0, []int{0, 2}, [][]int{{0, 2}, {2, 4}, {4, 6}}},
+		{`{cat,dog}`, `hotdog and catfish`, 0, []int{3, 6}, [][]int{{3, 6}, {11, 14}}},
+		{`*`, `ab`, 0, []int{0, 2}, [][]int{{0, 2}}},
+		{`*`, `a.b`, '.', []int{0, 1}, [][]int{{0, 1}, {2, 3}}},
+		{`x*`, `äxö`, 0, []int{2, 5}, [][]int{{2, 5}}},
+		{``, `äb`, 0, []int{0, 0}, [][]int{{0, 0}, {2, 2}, {3, 3}}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var sep []rune
+			if test.sep != 0 {
+				sep = append(sep, test.sep)
+			}
+			g, err := Compile(test.v, sep...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if v := g.FindIndex(test.s); !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, v)
+			}
+			exp := ""
+			if test.exp != nil {
+				exp = test.s[test.exp[0]:test.exp[1]]
+			}
+			if v := g.Find(test.s); v != exp {
+				t.Errorf("expected %q, got: %q", exp, v)
+			}
+			if v := g.FindAllIndex(test.s, -1); !reflect.DeepEqual(v, test.all) {
+				t.Errorf("expected %v, got: %v", test.all, v)
+			}
+			var all []string
+			for _, i := range test.all {
+				all = append(all, test.s[i[0]:i[1]])
+			}
+			if v := g.FindAll(test.s, -1); !reflect.DeepEqual(v, all) {
+				t.Errorf("expected %q, got: %q", all, v)
+			}
+			if len(test.all) > 1 {
+				if v := g.FindAllIndex(test.s, 1); !reflect.DeepEqual(v, test.all[:1]) {
+					t.Errorf("expected %v, got: %v", test.all[:1], v)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathological(t *testing.T) {
+	// without memoizing failures across the starting offsets, this takes
+	// exponential time
+	g := Must(`a*a*a*a*a*b`, ' ')
+	s := strings.Repeat("a", 60) + " aaaaab"
+	if v, exp := g.FindAllIndex(s, -1), [][]int{{61, 67}}; !reflect.DeepEqual(v, exp) {
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+	if v := g.FindIndex(s[:60]); v != nil {
+		t.Errorf("expected nil, got: %v", v)
+	}
+}
+
+func TestFindLong(t *testing.T) {
+	// without a dense memo and bounded wildcard retries, these take
+	// quadratic time or worse
+	s := strings.Repeat("a", 4096)
+	for i, g := range []*Glob{
+		Must(`*a*a*a*a*a*b`),
+		Must(`*a*a*a*a*a*b`, '/'),
+		Must(`**a**a**a**a**a**b`, '/'),
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if v := g.FindSubmatch(s); v != nil {
+				t.Errorf("expected nil, got: %v", v)
+			}
+			if v := g.FindAllIndex(s, -1); v != nil {
+				t.Errorf("expected nil, got: %v", v)
+			}
+			if v, exp := g.FindAllIndex(s+"b", -1), [][]int{{0, len(s) + 1}}; !reflect.DeepEqual(v, exp) {
+				t.Errorf("expected %v, got: %v", exp, v)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for i, test := range []struct {
+		v        string
+		template string
+		s        string
+		exp      string
+		ok       bool
+	}{
+		{`*.tar.gz`, `$1.tgz`, `logs.tar.gz`, `logs.tgz`, true},
+		{`*.tar.gz`, `$1.tgz`, `logs.zip`, ``, false},
+		{`*.tar.gz`, `${1}x.tgz`, `logs.tar.gz`, `logsx.tgz`, true},
+		{`*.tar.gz`, `#1.tgz`, `logs.tar.gz`, `logs.tgz`, true},
+		{`*-*.txt`, `$2-$1.txt`, `a-b.txt`, `b-a.txt`, true},
+		{`*-*.txt`, `#2_#1`, `a-b.txt`, `b_a`, true},
+		{`*`, `$0/$0`, `a`, `a/a`, true},
+		{`*`, `$$1 $1 $`, `a`, `$1 a $`, true},
+		{`*`, `# #x ${1 ${x}`, `a`, `# #x ${1 ${x}`, true},
+		{`*`, `$3.$10`, `a`, `.`, true},
+		{`{a,b}?`, `$2$1`, `bz`, `zb`, true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := Compile(test.v)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			v, ok := g.Expand(test.template, test.s)
+			if ok != test.ok {
+				t.Fatalf("expected %t, got: %t", test.ok, ok)
+			}
+			if v != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, v)
+			}
+			exp := test.s
+			if test.ok {
+				exp = test.exp
+			}
+			if v := g.Replace(test.s, test.template); v != exp {
+				t.Errorf("expected %q, got: %q", exp, v)
+			}
+		})
+	}
+}
+
 func TestCompileSeparators(t *testing.T) {
 	for i, test := range []struct {
 		s   string
@@ -398,6 +1024,8 @@ func TestQuote(t *testing.T) {
 		{`{foo*}`, `\{foo\*\}`},
 		{`*?\[]{}`, `\*\?\\\[\]\{\}`},
 		{`some text and *?\[]{}`, `some text and \*\?\\\[\]\{\}`},
+		{`file+(1).txt`, `file+\(1).txt`},
+		{`@(x)`, `@\(x)`},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			t.Logf("%q -> %q", test.s, test.exp)
@@ -408,6 +1036,13 @@ func TestQuote(t *testing.T) {
 			if _, err := Compile(s); err != nil {
 				t.Errorf("_, err := Compile(QuoteMeta(%q) = %q); err = %q", test.s, s, err)
 			}
+			g, err := CompileWithOptions(s, WithExtGlob())
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !g.Match(test.s) {
+				t.Errorf("expected %q to match %q", s, test.s)
+			}
 		})
 	}
 }
@@ -449,3 +1084,95 @@ const (
 	regexp_alternatives_combine_hard           = `^(abc.*[a-c]def|abc.[d-g]def|abc[zte].def)$`
 	fixture_alternatives_combine_hard          = "abczqdef"
 )
+
+func TestRegexp(t *testing.T) {
+	inputs := []string{
+		"", "a", "A", "ab", "abc", "ABC", "a.go", "b.GO", "src/a.go", "src/x/y.go",
+		"a/b", "a.b.c", "log.7", "log.30", "log.31", "log.007", "x-5", "x-12",
+		"é", "É", "日本", "\n", "a\nb", "-", "]", "1", "0x1F",
+	}
+	for i, test := range []struct {
+		pattern string
+		opts    []Option
+	}{
+		{"abc", nil},
+		{"*", nil},
+		{"*", []Option{WithSeparators('/')}},
+		{"*.go", []Option{WithSeparators('/')}},
+		{"*.go", []Option{WithSeparators('/'), WithCaseFold()}},
+		{"**.go", []Option{WithSeparators('/')}},
+		{"src/**/*.go", []Option{WithSeparators('/')}},
+		{"?", nil},
+		{"[!a]", nil},
+		{"?/?", []Option{WithSeparators('/')}},
+		{"[a-c]*", nil},
+		{"[!a-c]*", []Option{WithCaseFold()}},
+		{`[\]\-]`, nil},
+		{"[[:alpha:]]*", nil},
+		{"[![:alnum:][:punct:]]", nil},
+		{"[[:xdigit:]x]*", nil},
+		{`[\p{Han}]*`, nil},
+		{`[\P{L}]`, nil},
+		{"{a,b}*", nil},
+		{"{a,b}*", []Option{WithCaseFold()}},
+		{"a.{b,b.c}", nil},
+		{"log.{1..30}", nil},
+		{"log.{001..100}", nil},
+		{"x{-12..-3}", nil},
+		{"x{-5..5..5}", nil},
+		{"{a..c}", nil},
+		{"?(a|b)*", []Option{WithExtGlob()}},
+		{"+(a|b)c", []Option{WithExtGlob()}},
+		{"*(a|b).go", []Option{WithExtGlob(), WithSeparators('/')}},
+		{"@(abc|a.go)", []Option{WithExtGlob(), WithCaseFold()}},
+		{"[c-a]*", []Option{WithDialect(DialectPath)}},
+		{"[^c-a]*", []Option{WithDialect(DialectPath)}},
+		{"*/[^a]?", []Option{WithDialect(DialectPath)}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.pattern, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			re, err := g.Regexp()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for _, s := range inputs {
+				if exp, act := g.Match(s), re.MatchString(s); exp != act {
+					t.Errorf("%q: expected %t, got: %t (%s)", s, exp, act, re)
+				}
+			}
+		})
+	}
+}
+
+func TestRegexpError(t *testing.T) {
+	g, err := CompileWithOptions("!(a|b)", WithExtGlob())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := g.Regexp(); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	for _, pattern := range []string{
+		"{0..9223372036854775807..3}",
+		"{-9223372036854775807..9223372036854775807..2}",
+	} {
+		if _, err := Must(pattern).Regexp(); err == nil {
+			t.Errorf("%q: expected error, got: nil", pattern)
+		}
+	}
+	re, err := Must("{-9223372036854775807..9223372036854775807}").Regexp()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, s := range []string{"-9223372036854775807", "0", "9223372036854775807"} {
+		if !re.MatchString(s) {
+			t.Errorf("expected %q to match %s", s, re)
+		}
+	}
+	if s := "-9223372036854775808"; re.MatchString(s) {
+		t.Errorf("expected %q to not match %s", s, re)
+	}
+}
@@ -0,0 +1,371 @@
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kenshaw/glob/syntax"
+)
+
+// ErrPatternTooLong is the error returned when a pattern is longer than the
+// maximum length set with [WithMaxLength].
+var ErrPatternTooLong = errors.New("pattern too long")
+
+// DefaultOptions are the options used when unmarshaling a [Glob] that was
+// not created with any options.
+var DefaultOptions Options
+
+// Dialect is a pattern dialect.
+type Dialect int
+
+// Dialects.
+const (
+	// DialectGlob is the default pattern syntax, described by [Compile].
+	DialectGlob Dialect = iota
+	// DialectPath is the pattern syntax of [path.Match], described by
+	// [syntax.ParsePath], and matches exactly as [path.Match] does. The
+	// separator is always `/`, and malformed patterns return
+	// [path.ErrBadPattern].
+	DialectPath
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (d Dialect) String() string {
+	switch d {
+	case DialectGlob:
+		return "glob"
+	case DialectPath:
+		return "path"
+	}
+	return "Dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// MarshalText satisfies the [encoding.TextMarshaler] interface.
+func (d Dialect) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText satisfies the [encoding.TextUnmarshaler] interface.
+func (d *Dialect) UnmarshalText(buf []byte) error {
+	switch s := string(buf); s {
+	case "", "glob":
+		*d = DialectGlob
+	case "path":
+		*d = DialectPath
+	default:
+		return fmt.Errorf("unknown dialect %q", s)
+	}
+	return nil
+}
+
+// Options are options for compiling a [Glob].
+type Options struct {
+	// Separators are the characters not matched by `*` and `?`. Ignored by
+	// [DialectPath].
+	Separators []rune
+	// CaseFold matches case-insensitively, using Unicode simple case folding.
+	CaseFold bool
+	// MaxLength is the maximum length of a pattern in bytes. Zero means no
+	// limit.
+	MaxLength int
+	// Dialect is the pattern dialect.
+	Dialect Dialect
+	// NoEscape treats `\` as a regular character rather than an escape.
+	NoEscape bool
+	// ExtGlob enables the extended pattern lists of bash's extglob option:
+	//
+	//	`?(` pattern-list `)`  matches zero or one of the patterns
+	//	`*(` pattern-list `)`  matches zero or more of the patterns
+	//	`+(` pattern-list `)`  matches one or more of the patterns
+	//	`@(` pattern-list `)`  matches exactly one of the patterns
+	//	`!(` pattern-list `)`  matches anything except one of the patterns
+	//
+	// where pattern-list is a `|` separated list of patterns. As with `*`,
+	// `!(...)` does not match separators.
+	ExtGlob bool
+	// Pathname stops bracket expressions from matching separators, as with
+	// fnmatch's FNM_PATHNAME. The separator is `/` when no separators are
+	// set.
+	Pathname bool
+	// Period stops wildcards and bracket expressions from matching a leading
+	// period, or a period following a separator, as with fnmatch's
+	// FNM_PERIOD.
+	Period bool
+	// LeadingDir matches when the pattern matches the input up to a
+	// separator, ignoring the rest of the input, as with fnmatch's
+	// FNM_LEADING_DIR. For example, `foo/*` matches `foo/bar/baz`.
+	LeadingDir bool
+	// NoDotGlob stops wildcards and bracket expressions from matching a
+	// leading period in any path segment, as with bash when its dotglob
+	// option is unset. For example, neither `*` nor `**` match `.git/config`,
+	// while `.*/*` does. Segments are delimited by the separators, or `/`
+	// when there are no separators.
+	NoDotGlob bool
+	// DoubleStar makes `**` only special as a whole path segment, as with
+	// gitignore and most build tools. `a/**/b` matches `a/b` and `a/x/y/b`,
+	// and a trailing `/**` matches everything below a directory, while `**`
+	// within a segment (`a**b`) is `*`. Segments are delimited by `/`, which
+	// is the separator when no separators are set.
+	DoubleStar bool
+	// Strict rejects patterns with likely typos that are otherwise tolerated:
+	// an unterminated `{` (`{a,b`), a stray `}` or `]` (`a}b`), a trailing
+	// `\` and an empty alternative (`{a,}`). The error is a
+	// [*syntax.Error] locating the problem. Not supported by [DialectPath].
+	Strict bool
+}
+
+// Option is an option for compiling a [Glob].
+type Option func(*Options)
+
+// WithSeparators is a compile option to set the separators not matched by
+// `*` and `?`.
+func WithSeparators(separators ...rune) Option {
+	return func(opts *Options) {
+		opts.Separators = separators
+	}
+}
+
+// WithCaseFold is a compile option to match case-insensitively.
+func WithCaseFold() Option {
+	return func(opts *Options) {
+		opts.CaseFold = true
+	}
+}
+
+// WithMaxLength is a compile option to set the maximum length of a pattern in
+// bytes. Compiling a longer pattern returns [ErrPatternTooLong].
+func WithMaxLength(n int) Option {
+	return func(opts *Options) {
+		opts.MaxLength = n
+	}
+}
+
+// WithDialect is a compile option to set the pattern dialect.
+func WithDialect(dialect Dialect) Option {
+	return func(opts *Options) {
+		opts.Dialect = dialect
+	}
+}
+
+// WithNoEscape is a compile option to treat `\` as a regular character.
+func WithNoEscape() Option {
+	return func(opts *Options) {
+		opts.NoEscape = true
+	}
+}
+
+// WithExtGlob is a compile option to enable bash's extended pattern lists.
+// See [Options.ExtGlob].
+func WithExtGlob() Option {
+	return func(opts *Options) {
+		opts.ExtGlob = true
+	}
+}
+
+// WithPathname is a compile option to stop bracket expressions from matching
+// separators. See [Options.Pathname].
+func WithPathname() Option {
+	return func(opts *Options) {
+		opts.Pathname = true
+	}
+}
+
+// WithPeriod is a compile option to require leading periods to be matched
+// explicitly. See [Options.Period].
+func WithPeriod() Option {
+	return func(opts *Options) {
+		opts.Period = true
+	}
+}
+
+// WithLeadingDir is a compile option to match leading directories of the
+// input. See [Options.LeadingDir].
+func WithLeadingDir() Option {
+	return func(opts *Options) {
+		opts.LeadingDir = true
+	}
+}
+
+// WithNoDotGlob is a compile option to stop wildcards from matching hidden
+// names. See [Options.NoDotGlob].
+func WithNoDotGlob() Option {
+	return func(opts *Options) {
+		opts.NoDotGlob = true
+	}
+}
+
+// WithDoubleStar is a compile option to only treat `**` as special when it is
+// a whole path segment. See [Options.DoubleStar].
+func WithDoubleStar() Option {
+	return func(opts *Options) {
+		opts.DoubleStar = true
+	}
+}
+
+// WithStrict is a compile option to reject patterns with unbalanced braces,
+// stray closers, trailing escapes or empty alternatives. See
+// [Options.Strict].
+func WithStrict() Option {
+	return func(opts *Options) {
+		opts.Strict = true
+	}
+}
+
+// CompileWithOptions creates a [Glob] for the pattern using the options.
+func CompileWithOptions(pattern string, opts ...Option) (*Glob, error) {
+	g := New(opts...)
+	if err := g.compile(pattern, g.opts); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Compile creates a [Glob] for the pattern using the options.
+func (opts Options) Compile(pattern string) (*Glob, error) {
+	g := New()
+	if err := g.compile(pattern, opts); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// validate validates the pattern against the options.
+func (opts Options) validate(pattern string) error {
+	switch {
+	case opts.MaxLength != 0 && len(pattern) > opts.MaxLength:
+		return ErrPatternTooLong
+	case opts.Dialect != DialectGlob && opts.Dialect != DialectPath:
+		return fmt.Errorf("unknown dialect %v", opts.Dialect)
+	case opts.Dialect == DialectPath && opts.ExtGlob:
+		return fmt.Errorf("dialect %v does not support extglob", opts.Dialect)
+	case opts.Dialect == DialectPath && opts.DoubleStar:
+		return fmt.Errorf("dialect %v does not support doublestar", opts.Dialect)
+	case opts.Dialect == DialectPath && opts.Strict:
+		return fmt.Errorf("dialect %v does not support strict", opts.Dialect)
+	}
+	return nil
+}
+
+// parse parses the pattern using the dialect of the options.
+func (opts Options) parse(pattern string) (*syntax.Node, error) {
+	if opts.Dialect == DialectPath {
+		return syntax.ParsePath(pattern, opts.flags())
+	}
+	return syntax.Parse(syntax.NewLexerFlags(pattern, opts.flags()))
+}
+
+// separators returns the separators for the options.
+func (opts Options) separators() []rune {
+	if opts.Dialect == DialectPath || (opts.Pathname || opts.DoubleStar) && len(opts.Separators) == 0 {
+		return []rune{'/'}
+	}
+	return opts.Separators
+}
+
+// isZero reports whether the options are the default options.
+func (opts Options) isZero() bool {
+	return len(opts.Separators) == 0 &&
+		!opts.CaseFold &&
+		opts.MaxLength == 0 &&
+		opts.Dialect == DialectGlob &&
+		!opts.NoEscape &&
+		!opts.ExtGlob &&
+		!opts.Pathname &&
+		!opts.Period &&
+		!opts.LeadingDir &&
+		!opts.NoDotGlob &&
+		!opts.DoubleStar &&
+		!opts.Strict
+}
+
+// flags returns the syntax flags for the options.
+func (opts Options) flags() syntax.Flags {
+	var flags syntax.Flags
+	if opts.CaseFold {
+		flags |= syntax.FoldCase
+	}
+	if opts.NoEscape {
+		flags |= syntax.NoEscape
+	}
+	if opts.ExtGlob {
+		flags |= syntax.ExtGlob
+	}
+	if opts.Pathname {
+		flags |= syntax.Pathname
+	}
+	if opts.Period {
+		flags |= syntax.Period
+	}
+	if opts.LeadingDir {
+		flags |= syntax.LeadingDir
+	}
+	if opts.NoDotGlob {
+		flags |= syntax.NoDotGlob
+	}
+	if opts.DoubleStar {
+		flags |= syntax.DoubleStar
+	}
+	if opts.Strict {
+		flags |= syntax.Strict
+	}
+	return flags
+}
+
+// globJSON is the JSON object form of a [Glob].
+type globJSON struct {
+	Pattern    string  `json:"pattern"`
+	Separators string  `json:"separators,omitempty"`
+	CaseFold   bool    `json:"caseFold,omitempty"`
+	MaxLength  int     `json:"maxLength,omitempty"`
+	Dialect    Dialect `json:"dialect,omitzero"`
+	NoEscape   bool    `json:"noEscape,omitempty"`
+	ExtGlob    bool    `json:"extGlob,omitempty"`
+	Pathname   bool    `json:"pathname,omitempty"`
+	Period     bool    `json:"period,omitempty"`
+	LeadingDir bool    `json:"leadingDir,omitempty"`
+	NoDotGlob  bool    `json:"noDotGlob,omitempty"`
+	DoubleStar bool    `json:"doubleStar,omitempty"`
+	Strict     bool    `json:"strict,omitempty"`
+}
+
+// newGlobJSON creates the JSON object form for the pattern and options.
+func newGlobJSON(pattern string, opts Options) globJSON {
+	return globJSON{
+		Pattern:    pattern,
+		Separators: string(opts.Separators),
+		CaseFold:   opts.CaseFold,
+		MaxLength:  opts.MaxLength,
+		Dialect:    opts.Dialect,
+		NoEscape:   opts.NoEscape,
+		ExtGlob:    opts.ExtGlob,
+		Pathname:   opts.Pathname,
+		Period:     opts.Period,
+		LeadingDir: opts.LeadingDir,
+		NoDotGlob:  opts.NoDotGlob,
+		DoubleStar: opts.DoubleStar,
+		Strict:     opts.Strict,
+	}
+}
+
+// options returns the options of the JSON object form.
+func (v globJSON) options() Options {
+	var sep []rune
+	if v.Separators != "" {
+		sep = []rune(v.Separators)
+	}
+	return Options{
+		Separators: sep,
+		CaseFold:   v.CaseFold,
+		MaxLength:  v.MaxLength,
+		Dialect:    v.Dialect,
+		NoEscape:   v.NoEscape,
+		ExtGlob:    v.ExtGlob,
+		Pathname:   v.Pathname,
+		Period:     v.Period,
+		LeadingDir: v.LeadingDir,
+		NoDotGlob:  v.NoDotGlob,
+		DoubleStar: v.DoubleStar,
+		Strict:     v.Strict,
+	}
+}
@@ -0,0 +1,606 @@
+package glob
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/glob/syntax"
+)
+
+func TestCaseFold(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		s   string
+		sep rune
+		exp bool
+	}{
+		{`*.jpg`, `IMG_0001.JPG`, 0, true},
+		{`*.JPG`, `img_0001.jpg`, 0, true},
+		{`*.jpg`, `IMG_0001.PNG`, 0, false},
+		{`photo*`, `PHOTO-1.jpg`, 0, true},
+		{`*PHOTO*`, `my photo.jpg`, 0, true},
+		{`abc`, `ABC`, 0, true},
+		{`abc`, `ABD`, 0, false},
+		{`ab*ef`, `ABCDEF`, 0, true},
+		{`ab*ba`, `ABA`, 0, false},
+		{`a.*`, `A.B`, '.', true},
+		{`a.*`, `A.B.C`, '.', false},
+		{`*.b`, `A.B`, '.', true},
+		{`[a-c]at`, `CAT`, 0, true},
+		{`[!a-c]at`, `CAT`, 0, false},
+		{`[xyz]at`, `YAT`, 0, true},
+		{`[!xyz]at`, `YAT`, 0, false},
+		{`{cat,dog}`, `DOG`, 0, true},
+		{`{cat,dog}?`, `CATs`, 0, true},
+		{`*[!a][!a]**`, `xAcxb`, 0, true},
+		{`σ*`, `Σ-ς`, 0, true},
+		{`*ς`, `ΣΣ`, 0, true},
+		{`k*`, "K-kelvin", 0, true},
+		{"*K", `OK`, 0, true},
+		{"[K]", `k`, 0, true},
+		{`*k*`, "xKy", 0, true},
+		{`a?c`, `ABC`, 0, true},
+		{`straße`, `STRASSE`, 0, false},
+		{`{a..f}.txt`, `C.TXT`, 0, true},
+		{`{a..f}.txt`, `G.TXT`, 0, false},
+		{`x{1..3}y`, `X2Y`, 0, true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var sep []rune
+			if test.sep != 0 {
+				sep = append(sep, test.sep)
+			}
+			g, err := Options{Separators: sep, CaseFold: true}.Compile(test.v)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			t.Logf("%s", g.Matcher)
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestCompileWithOptions(t *testing.T) {
+	for i, test := range []struct {
+		v    string
+		opts []Option
+		s    string
+		exp  bool
+		err  error
+	}{
+		{`*.go`, nil, `a/b.go`, true, nil},
+		{`*.go`, []Option{WithSeparators('/')}, `a/b.go`, false, nil},
+		{`*.go`, []Option{WithSeparators('/'), WithCaseFold()}, `B.GO`, true, nil},
+		{`a\*`, nil, `a*`, true, nil},
+		{`a\*`, nil, `a\b`, false, nil},
+		{`a\*`, []Option{WithNoEscape()}, `a\b`, true, nil},
+		{`c:\dir\*`, []Option{WithNoEscape(), WithSeparators('\\')}, `c:\dir\file`, true, nil},
+		{`c:\dir\*`, []Option{WithNoEscape(), WithSeparators('\\')}, `c:\dir\sub\file`, false, nil},
+		{`abc*`, []Option{WithMaxLength(4)}, `abcd`, true, nil},
+		{`abcd*`, []Option{WithMaxLength(4)}, ``, false, ErrPatternTooLong},
+		{`*`, []Option{WithDialect(DialectGlob)}, `abc`, true, nil},
+		{`*`, []Option{WithDialect(DialectPath)}, `a/b`, false, nil},
+		{`**.go`, []Option{WithDialect(DialectPath)}, `a/b.go`, false, nil},
+		{`[^a]{b,c}`, []Option{WithDialect(DialectPath)}, `/{b,c}`, true, nil},
+		{`[!a]`, []Option{WithDialect(DialectPath)}, `!`, true, nil},
+		{`a[`, []Option{WithDialect(DialectPath)}, ``, false, path.ErrBadPattern},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.v, test.opts...)
+			switch {
+			case test.err != nil && err != test.err:
+				t.Fatalf("expected error %v, got: %v", test.err, err)
+			case test.err != nil:
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestNewUnmarshalText(t *testing.T) {
+	g := New(WithSeparators('/'), WithCaseFold())
+	if err := g.UnmarshalText([]byte("*.GO")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !g.Match("main.go") {
+		t.Errorf("expected match")
+	}
+	if g.Match("cmd/main.go") {
+		t.Errorf("expected no match")
+	}
+	if opts := g.Options(); !opts.CaseFold || string(opts.Separators) != "/" {
+		t.Errorf("expected options to be kept, got: %+v", opts)
+	}
+	if _, err := CompileWithOptions("*", WithDialect(Dialect(-1))); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	for i, test := range []struct {
+		v    string
+		opts []Option
+		exp  string
+	}{
+		{`*.go`, nil, `"*.go"`},
+		{`*.go`, []Option{WithSeparators('/')}, `{"pattern":"*.go","separators":"/"}`},
+		{`*.go`, []Option{WithSeparators('/', '.'), WithCaseFold()}, `{"pattern":"*.go","separators":"/.","caseFold":true}`},
+		{`a\*`, []Option{WithNoEscape(), WithMaxLength(10)}, `{"pattern":"a\\*","maxLength":10,"noEscape":true}`},
+		{`a[^b]`, []Option{WithDialect(DialectPath)}, `{"pattern":"a[^b]","dialect":"path"}`},
+		{`abc`, []Option{WithPathname(), WithPeriod()}, `{"pattern":"abc","pathname":true,"period":true}`},
+		{`*`, []Option{WithNoDotGlob()}, `{"pattern":"*","noDotGlob":true}`},
+		{`a/**/b`, []Option{WithDoubleStar()}, `{"pattern":"a/**/b","doubleStar":true}`},
+		{`{a,b}`, []Option{WithStrict()}, `{"pattern":"{a,b}","strict":true}`},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.v, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			buf, err := json.Marshal(g)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := string(buf); s != test.exp {
+				t.Errorf("expected %s, got: %s", test.exp, s)
+			}
+			var v struct {
+				G *Glob `json:"g"`
+			}
+			if err := json.Unmarshal([]byte(`{"g":`+string(buf)+`}`), &v); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if a, b := v.G.String(), g.String(); a != b {
+				t.Errorf("expected pattern %q, got: %q", b, a)
+			}
+			if !reflect.DeepEqual(v.G.Matcher, g.Matcher) {
+				t.Errorf("expected matcher %v, got: %v", g.Matcher, v.G.Matcher)
+			}
+			if a, b := newGlobJSON("", v.G.Options()), newGlobJSON("", g.Options()); a != b {
+				t.Errorf("expected options %+v, got: %+v", b, a)
+			}
+		})
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	defer func(opts Options) {
+		DefaultOptions = opts
+	}(DefaultOptions)
+	DefaultOptions = Options{Separators: []rune{'/'}}
+	var v struct {
+		A Glob  `json:"a"`
+		B *Glob `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"*.go","b":{"pattern":"*.go"}}`), &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if v.A.Match("a/b.go") {
+		t.Errorf("expected default separators to be used")
+	}
+	if !v.B.Match("a/b.go") {
+		t.Errorf("expected object options to be used")
+	}
+	if err := json.Unmarshal([]byte(`{"a":{"pattern":"*","dialect":"bogus"}}`), &v); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	// the string form would be unmarshaled with the default options
+	buf, err := json.Marshal(Must("*.go"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s, exp := string(buf), `{"pattern":"*.go"}`; s != exp {
+		t.Errorf("expected %s, got: %s", exp, s)
+	}
+	if err := json.Unmarshal(buf, v.B); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !v.B.Match("a/b.go") {
+		t.Errorf("expected options to be kept")
+	}
+}
+
+func TestUnmarshalJSONNull(t *testing.T) {
+	g := Must("*.go")
+	if err := json.Unmarshal([]byte(`null`), g); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := g.String(); s != "*.go" || !g.Match("a.go") {
+		t.Errorf("expected glob to be unchanged, got: %q", s)
+	}
+	var v struct {
+		A Glob  `json:"a"`
+		B *Glob `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":null,"b":null}`), &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if v.A.String() != "" || v.B != nil {
+		t.Errorf("expected zero globs, got: %q %v", v.A.String(), v.B)
+	}
+}
+
+func TestExtGlob(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		s   string
+		sep rune
+		exp bool
+	}{
+		{`a?(b|c)d`, `ad`, 0, true},
+		{`a?(b|c)d`, `abd`, 0, true},
+		{`a?(b|c)d`, `abcd`, 0, false},
+		{`a*(b|c)d`, `ad`, 0, true},
+		{`a*(b|c)d`, `abcbd`, 0, true},
+		{`a*(b|c)d`, `abxd`, 0, false},
+		{`a+(b|c)d`, `ad`, 0, false},
+		{`a+(b|c)d`, `acbd`, 0, true},
+		{`a@(b|c)d`, `abd`, 0, true},
+		{`a@(b|c)d`, `abcd`, 0, false},
+		{`a@(b|c)d`, `ad`, 0, false},
+		{`!(*.jpg)`, `a.png`, 0, true},
+		{`!(*.jpg)`, `a.jpg`, 0, false},
+		{`!(*.jpg|*.png)`, `a.png`, 0, false},
+		{`!(*.jpg|*.png)`, `a.gif`, 0, true},
+		{`!(foo)`, ``, 0, true},
+		{`!(foo)`, `foo`, 0, false},
+		{`!(foo)`, `fo`, 0, true},
+		{`!(foo)`, `fooo`, 0, true},
+		{`!(foo)bar`, `foobar`, 0, false},
+		{`!(foo)bar`, `fobar`, 0, true},
+		{`!(foo)*`, `foo`, 0, true},
+		{`a/!(b)/c`, `a/x/c`, '/', true},
+		{`a/!(b)/c`, `a/b/c`, '/', false},
+		{`a/!(b)/c`, `a/x/y/c`, '/', false},
+		{`!(a|b)`, `c/d`, '/', false},
+		{`*(a|ab)c`, `aababc`, 0, true},
+		{`+(*.)go`, `a.b.go`, 0, true},
+		{`@(a|b{c,d})`, `bd`, 0, true},
+		{`{x,@(a|b)}y`, `by`, 0, true},
+		{`x@(a,b)`, `xa,b`, 0, true},
+		{`x@(a,b)`, `xa`, 0, false},
+		{`x\@(a)`, `x@(a)`, 0, true},
+		{`x+\(a)`, `x+(a)`, 0, true},
+		{`x(a|b)`, `x(a|b)`, 0, true},
+		{`**(a)`, `ba`, 0, true},
+		{`+(ab)`, `abab`, 0, true},
+		{`+(ab)`, `aba`, 0, false},
+		{`*(a|)`, `aa`, 0, true},
+		{`!(*(a))`, `aa`, 0, false},
+		{`!(*(a))`, `ab`, 0, true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var sep []rune
+			if test.sep != 0 {
+				sep = append(sep, test.sep)
+			}
+			g, err := CompileWithOptions(test.v, WithSeparators(sep...), WithExtGlob())
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			t.Logf("%s", g.Matcher)
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestExtGlobDisabled(t *testing.T) {
+	g := Must(`a+(b|c)`)
+	if !g.Match(`a+(b|c)`) {
+		t.Errorf("expected match")
+	}
+	if g.Match(`ab`) {
+		t.Errorf("expected no match")
+	}
+}
+
+func TestDialectPath(t *testing.T) {
+	// patterns and names are generated from a small alphabet of special and
+	// multi-byte characters, and are checked against path.Match
+	r := rand.New(rand.NewPCG(1, 2))
+	gen := func(alphabet []string, n int) string {
+		var b strings.Builder
+		for range r.IntN(n + 1) {
+			b.WriteString(alphabet[r.IntN(len(alphabet))])
+		}
+		return b.String()
+	}
+	patternAlphabet := []string{"a", "b", "ä", "-", "/", "*", "?", "[", "]", "^", "!", "\\", "{", ",", "\xff", "[^a]", "[ab]"}
+	nameAlphabet := []string{"a", "b", "ä", "-", "/", "[", "\\", "\xff"}
+	// previously failing cases
+	patterns := []string{"*?b", "*?-", "*[^a][^a]"}
+	names := []string{"äb", "ba-b", "a-2-"}
+	for range 2000 {
+		patterns = append(patterns, gen(patternAlphabet, 8))
+	}
+	for i, pattern := range patterns {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, expErr := path.Match(pattern, "")
+			g, err := CompileWithOptions(pattern, WithDialect(DialectPath))
+			if err != expErr {
+				t.Fatalf("%q: expected error %v, got: %v", pattern, expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			for j := range 100 {
+				s := gen(nameAlphabet, 5)
+				if j < len(names) {
+					s = names[j]
+				}
+				exp, _ := path.Match(pattern, s)
+				if b := g.Match(s); b != exp {
+					t.Errorf("%q %q: expected %t, got: %t", pattern, s, exp, b)
+				}
+				if b := g.FindSubmatch(s) != nil; b != exp {
+					t.Errorf("%q %q: expected submatch %t, got: %t", pattern, s, exp, b)
+				}
+			}
+		})
+	}
+}
+
+func TestFnmatch(t *testing.T) {
+	// expected results are those of glibc's fnmatch with the same flags
+	for i, test := range []struct {
+		v    string
+		opts []Option
+		s    string
+		exp  bool
+	}{
+		{`*`, nil, `.a`, true},
+		{`*`, []Option{WithPeriod()}, `.a`, false},
+		{`*.c`, []Option{WithPeriod()}, `.c`, false},
+		{`.*`, []Option{WithPeriod()}, `.c`, true},
+		{`?b`, []Option{WithPeriod()}, `.b`, false},
+		{`[.]*`, []Option{WithPeriod()}, `.a`, false},
+		{`\.*`, []Option{WithPeriod()}, `.a`, true},
+		{`a/*`, []Option{WithPeriod()}, `a/.b`, true},
+		{`a/*`, []Option{WithPathname(), WithPeriod()}, `a/.b`, false},
+		{`a/.*`, []Option{WithPathname(), WithPeriod()}, `a/.b`, true},
+		{`*/*`, []Option{WithPathname(), WithPeriod()}, `.a/b`, false},
+		{`a*`, []Option{WithPathname(), WithPeriod()}, `a.c`, true},
+		{`*`, []Option{WithPathname()}, `a/b`, false},
+		{`a?b`, []Option{WithPathname()}, `a/b`, false},
+		{`a[/]b`, nil, `a/b`, true},
+		{`a[/]b`, []Option{WithPathname()}, `a/b`, false},
+		{`a[!x]b`, []Option{WithPathname()}, `a/b`, false},
+		{`[[:punct:]]*`, []Option{WithPathname()}, `/a`, false},
+		{`*[/]*`, []Option{WithPathname()}, `a/b`, false},
+		{`a\*`, nil, `a*`, true},
+		{`a\*`, []Option{WithNoEscape()}, `a\b`, true},
+		{`a\\b`, []Option{WithNoEscape()}, `a\\b`, true},
+		{`a\\b`, nil, `a\b`, true},
+		{`a/*`, []Option{WithPathname()}, `a/b/c`, false},
+		{`a/*`, []Option{WithPathname(), WithLeadingDir()}, `a/b/c`, true},
+		{`a`, []Option{WithLeadingDir()}, `a/b/c`, true},
+		{`a`, []Option{WithLeadingDir()}, `ab/c`, false},
+		{`a/b`, []Option{WithLeadingDir()}, `a/b/`, true},
+		{`*`, []Option{WithPathname(), WithPeriod(), WithLeadingDir()}, `a.b/.c/d`, true},
+		{`*/.*`, []Option{WithPathname(), WithPeriod(), WithLeadingDir()}, `a.b/.c/d`, true},
+		{`*/*`, []Option{WithPathname(), WithPeriod(), WithLeadingDir()}, `a.b/.c/d`, false},
+		{`A/*`, []Option{WithPathname(), WithCaseFold()}, `a/B`, true},
+		{`A/*`, []Option{WithPathname(), WithCaseFold(), WithPeriod()}, `a/.B`, false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.v, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestFnmatchMatchers(t *testing.T) {
+	for i, test := range []struct {
+		v   string
+		sep rune
+		s   string
+		exp bool
+	}{
+		{`*`, 0, `.a`, false},
+		{`*`, 0, `a.`, true},
+		{`*`, '/', `.a`, false},
+		{`*`, '/', `a/b`, false},
+		{`**`, '/', `a/b`, true},
+		{`**`, '/', `a/.b`, false},
+		{`**`, '/', `.a`, false},
+		{`**`, '/', `a./b.`, true},
+		{`?`, '/', `.`, false},
+		{`?`, '/', `a`, true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			opts := []Option{WithPeriod()}
+			if test.sep != 0 {
+				opts = append(opts, WithSeparators(test.sep))
+			}
+			g, err := CompileWithOptions(test.v, opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if _, ok := g.Matcher.(syntax.BacktrackMatcher); ok {
+				t.Errorf("expected built matcher, got: %v", g.Matcher)
+			}
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestNoDotGlob(t *testing.T) {
+	// expected results are those of bash's pathname expansion, with its
+	// dotglob option unset and globstar set
+	paths := []string{
+		".git", ".git/config", ".x", "a", "a/.env", "a/b.txt", "src",
+		"src/.f", "src/.hidden", "src/.hidden/y", "src/sub", "src/sub/z", "x",
+	}
+	for i, test := range []struct {
+		v    string
+		opts []Option
+		exp  []string
+	}{
+		{`*`, []Option{WithSeparators('/')}, []string{"a", "src", "x"}},
+		{`*/*`, []Option{WithSeparators('/')}, []string{"a/b.txt", "src/sub"}},
+		{`.*/*`, []Option{WithSeparators('/')}, []string{".git/config"}},
+		{`*/.*`, []Option{WithSeparators('/')}, []string{"a/.env", "src/.f", "src/.hidden"}},
+		{`?x`, []Option{WithSeparators('/')}, nil},
+		{`[.]x`, []Option{WithSeparators('/')}, nil},
+		{`src/**`, []Option{WithSeparators('/')}, []string{"src/sub", "src/sub/z"}},
+		{`src/*/*`, []Option{WithSeparators('/')}, []string{"src/sub/z"}},
+		{`.??*`, []Option{WithSeparators('/')}, []string{".git"}},
+		{`**`, []Option{WithSeparators('/')}, []string{"a", "a/b.txt", "src", "src/sub", "src/sub/z", "x"}},
+		{`*`, nil, []string{"a", "a/b.txt", "src", "src/sub", "src/sub/z", "x"}},
+		{`src/*`, nil, []string{"src/sub", "src/sub/z"}},
+		{`{a,src}/*`, nil, []string{"a/b.txt", "src/sub", "src/sub/z"}},
+		{`*/.*`, nil, []string{"a/.env", "src/.f", "src/.hidden", "src/.hidden/y"}},
+		{`*/.*/*`, nil, []string{"src/.hidden/y"}},
+		{`*`, []Option{WithSeparators('\\')}, []string{"a", "a/.env", "a/b.txt", "src", "src/.f", "src/.hidden", "src/.hidden/y", "src/sub", "src/sub/z", "x"}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.v, append(test.opts, WithNoDotGlob())...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var v []string
+			for _, s := range paths {
+				if b := g.Match(s); b != (g.FindSubmatch(s) != nil) {
+					t.Errorf("%q: expected submatch %t", s, b)
+				}
+				if g.Match(s) {
+					v = append(v, s)
+				}
+			}
+			if !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %q, got: %q", test.exp, v)
+			}
+		})
+	}
+}
+
+func TestDoubleStar(t *testing.T) {
+	for i, test := range []struct {
+		v    string
+		opts []Option
+		s    string
+		exp  bool
+	}{
+		{`a/**/b`, nil, `a/b`, true},
+		{`a/**/b`, nil, `a/x/b`, true},
+		{`a/**/b`, nil, `a/x/y/b`, true},
+		{`a/**/b`, nil, `a/xb`, false},
+		{`a/**/b`, nil, `ab`, false},
+		{`a/**/b`, nil, `a/b/c`, false},
+		{`**/b`, nil, `b`, true},
+		{`**/b`, nil, `x/y/b`, true},
+		{`**/b`, nil, `xb`, false},
+		{`a/**`, nil, `a/x`, true},
+		{`a/**`, nil, `a/x/y`, true},
+		{`a/**`, nil, `a`, false},
+		{`a/**`, nil, `ab/x`, false},
+		{`a**b`, nil, `axyb`, true},
+		{`a**b`, nil, `a/b`, false},
+		{`**.go`, nil, `main.go`, true},
+		{`**.go`, nil, `cmd/main.go`, false},
+		{`**/*.go`, nil, `main.go`, true},
+		{`**/*.go`, nil, `cmd/x/main.go`, true},
+		{`**`, nil, `a/b/c`, true},
+		{`src/**/*.{c,h}`, nil, `src/a/b.h`, true},
+		{`**/b`, []Option{WithNoDotGlob()}, `.git/b`, false},
+		{`**/b`, []Option{WithNoDotGlob()}, `x/b`, true},
+		{`a/**/b`, []Option{WithSeparators('/', '\\')}, `a/b`, true},
+		{`a/**/b`, []Option{WithExtGlob()}, `a/b`, true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.v, append(test.opts, WithDoubleStar())...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := g.Match(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+			if b := g.FindSubmatch(test.s) != nil; b != test.exp {
+				t.Errorf("expected submatch %t, got: %t", test.exp, b)
+			}
+		})
+	}
+	if _, err := CompileWithOptions("**", WithDoubleStar(), WithDialect(DialectPath)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	for i, test := range []struct {
+		v      string
+		opts   []Option
+		code   syntax.ErrorCode
+		offset int
+	}{
+		{`{a,b}`, nil, "", 0},
+		{`src/**/*.{c,h}`, []Option{WithDoubleStar()}, "", 0},
+		{`a\}\]`, nil, "", 0},
+		{`api/{v1,v2/users`, nil, syntax.ErrMissingBrace, 4},
+		{`api/v1}/users`, nil, syntax.ErrUnexpectedBrace, 6},
+		{`api/v1]/users`, nil, syntax.ErrUnexpectedBracket, 6},
+		{`api/v1/\`, nil, syntax.ErrTrailingEscape, 7},
+		{`api/{v1,}/users`, nil, syntax.ErrEmptyAlt, 8},
+		{`api/{v1,,v2}/users`, nil, syntax.ErrEmptyAlt, 8},
+		{`api/@(v1|v2/users`, []Option{WithExtGlob()}, syntax.ErrMissingParen, 4},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := CompileWithOptions(test.v, test.opts...); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			_, err := CompileWithOptions(test.v, append(test.opts, WithStrict())...)
+			if test.code == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			var e *syntax.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *syntax.Error, got: %v", err)
+			}
+			if e.Code != test.code || e.Offset != test.offset {
+				t.Errorf("expected %q at offset %d, got: %q at offset %d", test.code, test.offset, e.Code, e.Offset)
+			}
+		})
+	}
+	if _, err := CompileWithOptions("a", WithStrict(), WithDialect(DialectPath)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,144 @@
+package glob
+
+import (
+	"slices"
+
+	"github.com/kenshaw/glob/syntax"
+)
+
+// Set is a set of globs matched together against a single input, similar to
+// RE2's Set.
+//
+// Patterns are indexed by the literal text they must start (or, failing that,
+// end) with, so that only the patterns whose literal prefix or suffix occurs in
+// the input are fully matched. For typical sets, such as ownership or ignore
+// rules, the cost of a match grows much slower than the number of patterns.
+type Set struct {
+	sep    []rune
+	globs  []*Glob
+	prefix setTrie
+	suffix setTrie
+	rest   []int
+}
+
+// NewSet creates a new, empty set using the separators (if any) for all
+// patterns added to it.
+func NewSet(separators ...rune) *Set {
+	return &Set{sep: separators}
+}
+
+// CompileSet creates a [Set] for the patterns and separators.
+func CompileSet(patterns []string, separators ...rune) (*Set, error) {
+	s := NewSet(separators...)
+	for _, pattern := range patterns {
+		if _, err := s.Add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add compiles and adds the pattern to the set, returning its index.
+func (s *Set) Add(pattern string) (int, error) {
+	g, err := Compile(pattern, s.sep...)
+	if err != nil {
+		return -1, err
+	}
+	i := len(s.globs)
+	s.globs = append(s.globs, g)
+	switch prefix, suffix := syntax.LiteralPrefix(g.tree), syntax.LiteralSuffix(g.tree); {
+	case prefix != "" && len(prefix) >= len(suffix):
+		s.prefix.insert(prefix, false, i)
+	case suffix != "":
+		s.suffix.insert(suffix, true, i)
+	default:
+		s.rest = append(s.rest, i)
+	}
+	return i, nil
+}
+
+// Len returns the number of patterns in the set.
+func (s *Set) Len() int {
+	return len(s.globs)
+}
+
+// Glob returns the glob at index i in the set.
+func (s *Set) Glob(i int) *Glob {
+	return s.globs[i]
+}
+
+// Match returns the indices, in increasing order, of all patterns in the set
+// matching str. Returns nil when no pattern matches.
+func (s *Set) Match(str string) []int {
+	var v []int
+	for _, i := range s.candidates(str) {
+		if s.globs[i].Match(str) {
+			v = append(v, i)
+		}
+	}
+	return v
+}
+
+// MatchFirst returns the lowest index of the patterns in the set matching
+// str. Returns -1 when no pattern matches.
+func (s *Set) MatchFirst(str string) int {
+	for _, i := range s.candidates(str) {
+		if s.globs[i].Match(str) {
+			return i
+		}
+	}
+	return -1
+}
+
+// candidates returns the sorted indices of the patterns that could match str.
+func (s *Set) candidates(str string) []int {
+	v := slices.Clone(s.rest)
+	v = s.prefix.collect(v, str, false)
+	v = s.suffix.collect(v, str, true)
+	slices.Sort(v)
+	return v
+}
+
+// setTrie is a byte trie of pattern indices keyed by literal prefix or
+// (reversed) suffix.
+type setTrie struct {
+	ids      []int
+	children map[byte]*setTrie
+}
+
+// insert inserts the pattern index for the key.
+func (t *setTrie) insert(key string, reverse bool, i int) {
+	for j := range len(key) {
+		c := key[j]
+		if reverse {
+			c = key[len(key)-1-j]
+		}
+		if t.children == nil {
+			t.children = make(map[byte]*setTrie)
+		}
+		n, ok := t.children[c]
+		if !ok {
+			n = new(setTrie)
+			t.children[c] = n
+		}
+		t = n
+	}
+	t.ids = append(t.ids, i)
+}
+
+// collect appends the pattern indices of all keys that are a prefix (or
+// suffix, when reverse is true) of str to v.
+func (t *setTrie) collect(v []int, str string, reverse bool) []int {
+	for j := 0; t != nil; j++ {
+		v = append(v, t.ids...)
+		if j == len(str) {
+			break
+		}
+		c := str[j]
+		if reverse {
+			c = str[len(str)-1-j]
+		}
+		t = t.children[c]
+	}
+	return v
+}
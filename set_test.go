@@ -0,0 +1,89 @@
+package glob
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	patterns := []string{
+		`src/*/main.go`,
+		`src/**`,
+		`*.go`,
+		`**_test.go`,
+		`{docs,examples}/**.md`,
+		`[a-z]*`,
+		`README.md`,
+		`src/api/**`,
+	}
+	s, err := CompileSet(patterns, '/')
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := s.Len(); n != len(patterns) {
+		t.Fatalf("expected %d, got: %d", len(patterns), n)
+	}
+	for i, test := range []struct {
+		s   string
+		exp []int
+	}{
+		{`src/api/main.go`, []int{0, 1, 7}},
+		{`src/api/main_test.go`, []int{1, 3, 7}},
+		{`main.go`, []int{2, 5}},
+		{`docs/a/b.md`, []int{4}},
+		{`README.md`, []int{6}},
+		{`Makefile`, nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := s.Match(test.s)
+			if !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, v)
+			}
+			first := -1
+			if len(test.exp) != 0 {
+				first = test.exp[0]
+			}
+			if i := s.MatchFirst(test.s); i != first {
+				t.Errorf("expected %d, got: %d", first, i)
+			}
+			for j, p := range patterns {
+				if b, exp := Must(p, '/').Match(test.s), s.Glob(j).Match(test.s); b != exp {
+					t.Errorf("pattern %d %q: expected %t, got: %t", j, p, exp, b)
+				}
+			}
+		})
+	}
+	if _, err := s.Add(`[`); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	s := NewSet('/')
+	var globs []*Glob
+	for i := range 500 {
+		for _, p := range []string{
+			fmt.Sprintf("team%d/**", i),
+			fmt.Sprintf("*/svc%d.go", i),
+			fmt.Sprintf("{a,b}%d", i),
+		} {
+			if _, err := s.Add(p); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			globs = append(globs, Must(p, '/'))
+		}
+	}
+	for _, str := range []string{"team42/x/y", "pkg/svc7.go", "b499", "team1", "x"} {
+		var exp []int
+		for i, g := range globs {
+			if g.Match(str) {
+				exp = append(exp, i)
+			}
+		}
+		if v := s.Match(str); !reflect.DeepEqual(v, exp) {
+			t.Errorf("%q: expected %v, got: %v", str, exp, v)
+		}
+	}
+}
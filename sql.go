@@ -0,0 +1,73 @@
+package glob
+
+import (
+	"github.com/kenshaw/glob/syntax"
+)
+
+// SQLFilter is a SQL predicate filtering values by a glob, as returned by
+// [Glob.Like] and [Glob.SQLiteGlob].
+type SQLFilter struct {
+	// Op is the SQL operator, `LIKE` or `GLOB`.
+	Op string
+	// Pattern is the pattern for the operator.
+	Pattern string
+	// Escape is the escape character for the ESCAPE clause of a LIKE
+	// pattern. Empty for a GLOB pattern.
+	Escape string
+	// Residual is the glob the values matched by the pattern must be filtered
+	// with, when the pattern is not an exact translation of the glob. Nil when
+	// the translation is exact.
+	Residual *Glob
+}
+
+// Exact reports whether the pattern is an exact translation of the glob.
+func (f SQLFilter) Exact() bool {
+	return f.Residual == nil
+}
+
+// Expr returns the SQL expression applying the filter to the column, and the
+// arguments for its `?` placeholders.
+func (f SQLFilter) Expr(column string) (string, []any) {
+	if f.Escape != "" {
+		return column + " " + f.Op + " ? ESCAPE ?", []any{f.Pattern, f.Escape}
+	}
+	return column + " " + f.Op + " ?", []any{f.Pattern}
+}
+
+// Match reports whether a value matched by the pattern matches the glob,
+// filtering it with the residual glob, if any.
+func (f SQLFilter) Match(s string) bool {
+	return f.Residual == nil || f.Residual.Match(s)
+}
+
+// Like returns a SQL LIKE filter for the glob. Where the glob cannot be
+// translated exactly, such as for alternatives (`{a,b}`), the filter has a
+// LIKE pattern matching a superset of the glob, and the glob as its residual.
+// The translation assumes a case-sensitive LIKE, such as SQLite's with
+// `PRAGMA case_sensitive_like`. See [syntax.ToLike].
+func (g *Glob) Like() SQLFilter {
+	s, exact := syntax.ToLike(g.tree, g.opts.separators(), g.opts.flags())
+	return g.sqlFilter("LIKE", s, string(syntax.LikeEscape), exact)
+}
+
+// SQLiteGlob returns a SQLite GLOB filter for the glob. Where the glob cannot
+// be translated exactly, such as for alternatives (`{a,b}`), the filter has a
+// GLOB pattern matching a superset of the glob, and the glob as its residual.
+// See [syntax.ToSQLiteGlob].
+func (g *Glob) SQLiteGlob() SQLFilter {
+	s, exact := syntax.ToSQLiteGlob(g.tree, g.opts.separators(), g.opts.flags())
+	return g.sqlFilter("GLOB", s, "", exact)
+}
+
+// sqlFilter creates a SQL filter for the glob.
+func (g *Glob) sqlFilter(op, pattern, escape string, exact bool) SQLFilter {
+	f := SQLFilter{
+		Op:      op,
+		Pattern: pattern,
+		Escape:  escape,
+	}
+	if !exact {
+		f.Residual = g
+	}
+	return f
+}
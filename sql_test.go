@@ -0,0 +1,229 @@
+package glob
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSQLFilter(t *testing.T) {
+	for i, test := range []struct {
+		pattern   string
+		opts      []Option
+		like      string
+		likeExact bool
+		glob      string
+		globExact bool
+	}{
+		{"abc", nil, "abc", true, "abc", true},
+		{"a%b_c", nil, `a\%b\_c`, true, "a%b_c", true},
+		{`a\*b\?c\[d\\e`, nil, `a*b?c[d\\e`, true, "a[*]b[?]c[[]d\\e", true},
+		{"*.go", nil, "%.go", true, "*.go", true},
+		{"*.go", []Option{WithSeparators('/')}, "%.go", false, "*.go", false},
+		{"src/**.go", []Option{WithSeparators('/')}, "src/%.go", true, "src/*.go", true},
+		{"a?c", nil, "a_c", true, "a?c", true},
+		{"a?c", []Option{WithSeparators('/')}, "a_c", false, "a[^/]c", true},
+		{"a?c", []Option{WithSeparators('/', ']', '-')}, "a_c", false, "a[^]/-]c", true},
+		{"[a-c]x", nil, "_x", false, "[a-c]x", true},
+		{"[!abc]x", nil, "_x", false, "[^a-c]x", true},
+		{`[\]\-^]`, nil, "_", false, "[]^-]", true},
+		{`[\^]`, nil, "_", false, "^", true},
+		{`[\^\-]`, nil, "_", false, "[-^]", true},
+		{`[!\^]`, nil, "_", false, "[^^]", true},
+		{"[a-cx-z_]", nil, "_", false, "[_a-cx-z]", true},
+		{"[[:digit:]]", nil, "_", false, "?", false},
+		{"[a[:digit:]]", nil, "_", false, "?", false},
+		{"{a,b}.go", nil, "%.go", false, "*.go", false},
+		{"*{a,b}*", nil, "%", false, "*", false},
+		{"log.{1..9}", nil, "log.%", false, "log.*", false},
+		{"aB1", []Option{WithCaseFold()}, "__1", false, "[Aa][Bb]1", true},
+		{"k", []Option{WithCaseFold()}, "_", false, "[Kk\u212a]", true},
+		{"[ab]", []Option{WithCaseFold()}, "_", false, "[ABab]", true},
+		{"[a-b]", []Option{WithCaseFold()}, "_", false, "?", false},
+		{"@(a|b)", []Option{WithExtGlob()}, "%", false, "*", false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.pattern, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			like := g.Like()
+			if like.Pattern != test.like || like.Exact() != test.likeExact {
+				t.Errorf("expected LIKE %q (exact: %t), got: %q (exact: %t)", test.like, test.likeExact, like.Pattern, like.Exact())
+			}
+			glob := g.SQLiteGlob()
+			if glob.Pattern != test.glob || glob.Exact() != test.globExact {
+				t.Errorf("expected GLOB %q (exact: %t), got: %q (exact: %t)", test.glob, test.globExact, glob.Pattern, glob.Exact())
+			}
+			for _, f := range []SQLFilter{like, glob} {
+				if f.Exact() != (f.Residual == nil) || !f.Exact() && f.Residual != g {
+					t.Errorf("expected residual glob when inexact, got: %v", f.Residual)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLFilterMatch(t *testing.T) {
+	inputs := []string{
+		"", "a", "A", "b", "k", "K", "\u212a", "ab", "abc", "ABC", "aBc", "a/c",
+		"a.go", "b.go", "x.go", "src/a.go", "src/x/a.go", "log.1", "log.10",
+		"-", "]", "^", "_", "%", "a_c", "a%c", "a\\c", "ax", "dx", "日本",
+	}
+	for i, test := range []struct {
+		pattern string
+		opts    []Option
+	}{
+		{"abc", nil},
+		{"a_c", nil},
+		{"a%c", nil},
+		{`a\\c`, nil},
+		{"*.go", nil},
+		{"*.go", []Option{WithSeparators('/')}},
+		{"src/**", []Option{WithSeparators('/')}},
+		{"a?c", nil},
+		{"a?c", []Option{WithSeparators('/')}},
+		{"?", []Option{WithSeparators(']', '-', '^')}},
+		{"[a-c]*", nil},
+		{"[!a-c]x", nil},
+		{`[\]\-^]`, nil},
+		{`[\^]`, nil},
+		{`[!\^\-]`, nil},
+		{"[[:alpha:]]", nil},
+		{"{a,b}.go", nil},
+		{"log.{1..9}", nil},
+		{"abc", []Option{WithCaseFold()}},
+		{"k", []Option{WithCaseFold()}},
+		{"[!ak]", []Option{WithCaseFold()}},
+		{"[a-c]*", []Option{WithCaseFold()}},
+		{"+(a|b)c", []Option{WithExtGlob()}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := CompileWithOptions(test.pattern, test.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			like, glob := g.Like(), g.SQLiteGlob()
+			for _, s := range inputs {
+				exp := g.Match(s)
+				if l := likeMatch(like.Pattern, s); l != exp && (like.Exact() || exp) {
+					t.Errorf("%q: expected LIKE %q to return %t, got: %t", s, like.Pattern, exp, l)
+				}
+				if l := sqliteGlobMatch(glob.Pattern, s); l != exp && (glob.Exact() || exp) {
+					t.Errorf("%q: expected GLOB %q to return %t, got: %t", s, glob.Pattern, exp, l)
+				}
+				for _, f := range []SQLFilter{like, glob} {
+					if f.Match(s) != exp && !f.Exact() {
+						t.Errorf("%q: expected residual match %t", s, exp)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSQLFilterExpr(t *testing.T) {
+	g, err := Compile("{a,b}_*")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expr, args := g.Like().Expr("name")
+	if exp := "name LIKE ? ESCAPE ?"; expr != exp {
+		t.Errorf("expected %q, got: %q", exp, expr)
+	}
+	if exp := []any{`%\_%`, `\`}; !reflect.DeepEqual(args, exp) {
+		t.Errorf("expected %v, got: %v", exp, args)
+	}
+	expr, args = g.SQLiteGlob().Expr("name")
+	if exp := "name GLOB ?"; expr != exp {
+		t.Errorf("expected %q, got: %q", exp, expr)
+	}
+	if exp := []any{"*_*"}; !reflect.DeepEqual(args, exp) {
+		t.Errorf("expected %v, got: %v", exp, args)
+	}
+}
+
+// likeMatch matches s against a case-sensitive SQL LIKE pattern, with `\` as
+// the escape character.
+func likeMatch(pattern, s string) bool {
+	p, v := []rune(pattern), []rune(s)
+	var match func(i, j int) bool
+	match = func(i, j int) bool {
+		for ; i < len(p); i++ {
+			switch c := p[i]; {
+			case c == '%':
+				for k := j; k <= len(v); k++ {
+					if match(i+1, k) {
+						return true
+					}
+				}
+				return false
+			case c == '_':
+				if j == len(v) {
+					return false
+				}
+			default:
+				if c == '\\' && i+1 < len(p) {
+					i++
+				}
+				if j == len(v) || v[j] != p[i] {
+					return false
+				}
+			}
+			j++
+		}
+		return j == len(v)
+	}
+	return match(0, 0)
+}
+
+// sqliteGlobMatch matches s against a SQLite GLOB pattern.
+func sqliteGlobMatch(pattern, s string) bool {
+	p, v := []rune(pattern), []rune(s)
+	var match func(i, j int) bool
+	match = func(i, j int) bool {
+		for ; i < len(p); i++ {
+			switch c := p[i]; c {
+			case '*':
+				for k := j; k <= len(v); k++ {
+					if match(i+1, k) {
+						return true
+					}
+				}
+				return false
+			case '?':
+				if j == len(v) {
+					return false
+				}
+			case '[':
+				if j == len(v) {
+					return false
+				}
+				i++
+				not := i < len(p) && p[i] == '^'
+				if not {
+					i++
+				}
+				var seen bool
+				for first := true; i < len(p) && (first || p[i] != ']'); i, first = i+1, false {
+					if i+2 < len(p) && p[i+1] == '-' && p[i+2] != ']' {
+						seen = seen || p[i] <= v[j] && v[j] <= p[i+2]
+						i += 2
+						continue
+					}
+					seen = seen || p[i] == v[j]
+				}
+				if seen == not {
+					return false
+				}
+			default:
+				if j == len(v) || v[j] != c {
+					return false
+				}
+			}
+			j++
+		}
+		return j == len(v)
+	}
+	return match(0, 0)
+}
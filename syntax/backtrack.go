@@ -0,0 +1,603 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BacktrackMatcher matches a node tree directly by backtracking, recording
+// the span of the input matched by each top-level wildcard term of the
+// pattern.
+//
+// Unlike the matchers built by [Node.Match], a backtracking matcher keeps the
+// structure of the pattern, which makes it slower but allows it to report
+// what each wildcard term matched. Failed attempts to match a node at an
+// offset are memoized, so that matching takes polynomial time.
+type BacktrackMatcher struct {
+	tree  *Node
+	sep   []rune
+	flags Flags
+	terms map[*Node]int
+	ids   map[*Node]int
+	n     int
+}
+
+// NewBacktrack creates a backtracking matcher for the tree using the flags.
+func NewBacktrack(tree *Node, sep []rune, flags Flags) BacktrackMatcher {
+	m := BacktrackMatcher{
+		tree:  tree,
+		sep:   sep,
+		flags: flags,
+		terms: make(map[*Node]int),
+		ids:   make(map[*Node]int),
+		n:     minLen(tree),
+	}
+	var walk func(*Node)
+	walk = func(n *Node) {
+		m.ids[n] = len(m.ids)
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(tree)
+	terms := []*Node{tree}
+	if tree.Type == Pattern {
+		terms = tree.Children
+	}
+	for _, n := range terms {
+		if isWildcard(n.Type) {
+			m.terms[n] = len(m.terms)
+		}
+	}
+	return m
+}
+
+// Match satisfies the [Matcher] interface.
+func (m BacktrackMatcher) Match(s string) bool {
+	b := backtrack{m: m, s: s}
+	return b.node(m.tree, 0, cont{f: b.end})
+}
+
+// Len satisfies the [Matcher] interface.
+func (m BacktrackMatcher) Len() int {
+	return m.n
+}
+
+// NumTerms returns the number of wildcard terms captured by the matcher.
+func (m BacktrackMatcher) NumTerms() int {
+	return len(m.terms)
+}
+
+// Submatch matches s against the tree, returning a pair of byte offsets into s
+// for the span matched by each wildcard term. Returns nil when s does not
+// match.
+func (m BacktrackMatcher) Submatch(s string) []int {
+	b := backtrack{m: m, s: s, caps: make([]int, 2*len(m.terms))}
+	if !b.node(m.tree, 0, cont{f: b.end}) {
+		return nil
+	}
+	return b.caps
+}
+
+// MatchPrefix reports whether s is a prefix of some string matching the tree,
+// that is, whether s could match if more input were appended to it.
+func (m BacktrackMatcher) MatchPrefix(s string) bool {
+	b := backtrack{m: m, s: s, partial: true}
+	return b.node(m.tree, 0, cont{f: b.end})
+}
+
+// Find returns a pair of byte offsets into s identifying the leftmost match of
+// the tree in s that starts at or after start. Of the matches starting at
+// that offset, the one preferred by the backtracking order is returned (that
+// is, wildcards match as much as they can). Returns nil when there is no
+// match.
+func (m BacktrackMatcher) Find(s string, start int) []int {
+	b := backtrack{m: m, s: s}
+	return b.find(start)
+}
+
+// FindAll returns pairs of byte offsets into s identifying successive
+// non-overlapping matches of the tree in s, as found by [BacktrackMatcher.Find].
+// If n >= 0, at most n matches are returned. As with
+// [regexp.Regexp.FindAllStringIndex], empty matches abutting a preceding match
+// are ignored. Returns nil when there is no match.
+func (m BacktrackMatcher) FindAll(s string, n int) [][]int {
+	if n < 0 {
+		n = len(s) + 1
+	}
+	b := backtrack{m: m, s: s}
+	var v [][]int
+	for pos, prev := 0, -1; len(v) < n && pos <= len(s); {
+		i := b.find(pos)
+		if i == nil {
+			break
+		}
+		accept := true
+		if i[1] == i[0] {
+			// empty match; skip it when it abuts the previous match, and
+			// advance past the next rune
+			accept = i[0] != prev
+			_, w := utf8.DecodeRuneInString(s[i[1]:])
+			pos = i[1] + max(w, 1)
+		} else {
+			pos = i[1]
+		}
+		prev = i[1]
+		if accept {
+			v = append(v, i)
+		}
+	}
+	return v
+}
+
+// find returns the leftmost match starting at or after start, as with
+// [BacktrackMatcher.Find]. The continuation always succeeds, so failures are
+// memoized across the starting offsets, and across calls.
+func (b *backtrack) find(start int) []int {
+	s := b.s
+	for i := start; i <= len(s); {
+		end := -1
+		if b.node(b.m.tree, i, cont{f: func(j int) bool {
+			end = j
+			return true
+		}}) {
+			return []int{i, end}
+		}
+		if i == len(s) {
+			break
+		}
+		_, w := utf8.DecodeRuneInString(s[i:])
+		i += w
+	}
+	return nil
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (m BacktrackMatcher) String() string {
+	return "<backtrack:" + m.tree.String() + ">"
+}
+
+// backtrack holds the state for a single backtracking run.
+type backtrack struct {
+	m       BacktrackMatcher
+	s       string
+	caps    []int
+	partial bool
+	// conts are the ids of the continuations created by the run.
+	conts map[contKey]int
+	// memos are the memos of the nodes for each continuation, indexed by
+	// the continuation id and node id.
+	memos []*memo
+	// sub is the run matching the alternatives of `!(...)` lists.
+	sub *backtrack
+	// seps and periods are the positions of the first separator, and the
+	// first period that must be matched explicitly, at or after each
+	// position, created when first needed.
+	seps    []int
+	periods []int
+}
+
+// cont is a continuation of a backtracking run, called with the end position
+// of each candidate match of a node. Continuations with the same id are
+// equivalent, which allows failures to be memoized. The id of the
+// continuation ending the run is 0.
+type cont struct {
+	id int
+	f  func(int) bool
+}
+
+// contKey identifies a continuation of a run, which continues matching from
+// the node n after the continuation with id parent. The meaning of i depends
+// on the node.
+type contKey struct {
+	n      *Node
+	i      int
+	parent int
+}
+
+// memo records the failed attempts to match a node with a continuation.
+type memo struct {
+	// failed is a bitset of the offsets at which the node failed to match.
+	failed []uint64
+	// lo and hi are the range of end positions of a wildcard that are known
+	// to fail, when hi is not -1.
+	lo, hi int
+}
+
+// memo returns the memo of the node for the continuation.
+func (b *backtrack) memo(n *Node, k cont) *memo {
+	i := k.id*len(b.m.ids) + b.m.ids[n]
+	if i >= len(b.memos) {
+		b.memos = append(b.memos, make([]*memo, i+1-len(b.memos))...)
+	}
+	if b.memos[i] == nil {
+		b.memos[i] = &memo{
+			failed: make([]uint64, len(b.s)/64+1),
+			hi:     -1,
+		}
+	}
+	return b.memos[i]
+}
+
+// cont returns the continuation for the key, calling f.
+func (b *backtrack) cont(key contKey, f func(int) bool) cont {
+	if b.conts == nil {
+		b.conts = make(map[contKey]int)
+	}
+	id, ok := b.conts[key]
+	if !ok {
+		id = len(b.conts) + 1
+		b.conts[key] = id
+	}
+	return cont{id: id, f: f}
+}
+
+// seq matches the children of the pattern node from index j in order starting
+// at s[i:], calling k with the end position of each candidate match until k
+// returns true.
+func (b *backtrack) seq(n *Node, j, i int, k cont) bool {
+	if j == len(n.Children) {
+		return k.f(i)
+	}
+	return b.node(n.Children[j], i, b.cont(contKey{n, j + 1, k.id}, func(e int) bool {
+		return b.seq(n, j+1, e, k)
+	}))
+}
+
+// node matches a single node starting at s[i:], calling k with the end
+// position of each candidate match until k returns true. Candidates are tried
+// longest first.
+func (b *backtrack) node(n *Node, i int, k cont) bool {
+	m := b.memo(n, k)
+	if m.failed[i/64]&(1<<(i%64)) != 0 {
+		return false
+	}
+	if b.match(n, i, k, m) {
+		return true
+	}
+	m.failed[i/64] |= 1 << (i % 64)
+	return false
+}
+
+// match matches a single node starting at s[i:], as with node, without
+// checking the memo m of the node.
+func (b *backtrack) match(n *Node, i int, k cont, m *memo) bool {
+	if c, ok := b.m.terms[n]; ok && b.caps != nil {
+		next := k.f
+		k.f = func(j int) bool {
+			b.caps[2*c], b.caps[2*c+1] = i, j
+			return next(j)
+		}
+	}
+	if b.partial && i == len(b.s) {
+		// the input ran out, so any remaining nodes could match appended
+		// input
+		return true
+	}
+	switch n.Type {
+	case Nothing:
+		return k.f(i)
+	case Pattern:
+		return b.seq(n, 0, i, k)
+	case AnyOf, ExactlyOne:
+		return b.alt(n, i, k)
+	case ZeroOrOne:
+		return b.alt(n, i, k) || k.f(i)
+	case ZeroOrMore:
+		return b.repeat(n, i, k)
+	case OneOrMore:
+		return b.alt(n, i, b.cont(contKey{n, -1, k.id}, func(j int) bool {
+			return b.repeat(n, j, k)
+		}))
+	case Not:
+		return b.not(n, i, k)
+	case Text:
+		t := n.Value.(TextData)
+		fold := b.m.flags&FoldCase != 0
+		if b.partial && textPrefix(t.Text, b.s[i:], fold) != -1 {
+			return true
+		}
+		j := textPrefix(b.s[i:], t.Text, fold)
+		if j == -1 {
+			return false
+		}
+		return k.f(i + j)
+	case Any:
+		if b.period(i) {
+			return false
+		}
+		return b.wildcard(m, i, b.periodEnd(i, b.sepEnd(i)), k.f)
+	case Super:
+		if b.period(i) {
+			return false
+		}
+		return b.wildcard(m, i, b.periodEnd(i, len(b.s)), k.f)
+	case Sequence:
+		return b.sequence(n.Value.(SequenceData), i, k)
+	case Single, List, Range, Class, CharSet:
+		r, w := utf8.DecodeRuneInString(b.s[i:])
+		switch {
+		case w == 0, b.period(i), !b.single(n, r),
+			b.m.flags&Pathname != 0 && b.isSep(r):
+			return false
+		}
+		return k.f(i + w)
+	}
+	return false
+}
+
+// alt matches each of the alternatives of the node starting at s[i:], calling
+// k with the end position of each candidate match until k returns true.
+func (b *backtrack) alt(n *Node, i int, k cont) bool {
+	for _, c := range n.Children {
+		if b.node(c, i, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeat matches zero or more of the alternatives of the node starting at
+// s[i:], calling k with the end position of each candidate match until k
+// returns true. Each repetition must consume input.
+func (b *backtrack) repeat(n *Node, i int, k cont) bool {
+	// the continuation depends on the start of the repetition
+	return b.alt(n, i, b.cont(contKey{n, i, k.id}, func(j int) bool {
+		return j > i && b.repeat(n, j, k)
+	})) || k.f(i)
+}
+
+// not matches any run of non-separator characters starting at s[i:] that is
+// not matched by any of the alternatives of the node, calling k with the end
+// position of each candidate match until k returns true. Candidates are tried
+// longest first.
+func (b *backtrack) not(n *Node, i int, k cont) bool {
+	end := b.sepEnd(i)
+	if b.period(i) {
+		return false
+	}
+	if b.sub == nil {
+		b.sub = &backtrack{m: b.m, s: b.s}
+	}
+	sub := b.sub
+	return b.longest(i, end, func(j int) bool {
+		if b.partial && j == len(b.s) {
+			// appended input could stop the alternatives from matching
+			return k.f(j)
+		}
+		if sub.alt(n, i, sub.cont(contKey{n, j, 0}, func(e int) bool {
+			return e == j
+		})) {
+			return false
+		}
+		return k.f(j)
+	})
+}
+
+// sequence matches a value of the sequence starting at s[i:], calling k with
+// the end position of each candidate match until k returns true. Candidates
+// are tried longest first.
+func (b *backtrack) sequence(d SequenceData, i int, k cont) bool {
+	end := min(i+d.maxLen(), len(b.s))
+	if b.partial && !d.Chars && end == len(b.s) &&
+		strings.TrimLeft(b.s[i:], "-0123456789") == "" {
+		// the input ran out in what could be the start of a value
+		return true
+	}
+	fold := b.m.flags&FoldCase != 0 && d.Chars
+	return b.longest(i, end, func(j int) bool {
+		ok := d.Contains(b.s[i:j])
+		if !ok && fold && j > i {
+			r, _ := utf8.DecodeRuneInString(b.s[i:j])
+			ok = b.fold(r, func(r rune) bool {
+				return d.Contains(string(r))
+			})
+		}
+		return ok && k.f(j)
+	})
+}
+
+// longest calls k with each rune boundary from end down to start, until k
+// returns true.
+func (b *backtrack) longest(start, end int, k func(int) bool) bool {
+	for j := end; ; {
+		if k(j) {
+			return true
+		}
+		if j <= start {
+			return false
+		}
+		_, w := utf8.DecodeLastRuneInString(b.s[start:j])
+		j -= w
+	}
+}
+
+// wildcard calls k with each end position from end to start, as with longest,
+// skipping the end positions known to fail from the memo m of the wildcard.
+// As the end position only depends on the start of the wildcard when there
+// is a separator or period in between, each end position is usually only
+// tried once, rather than once for every start.
+func (b *backtrack) wildcard(m *memo, start, end int, k func(int) bool) bool {
+	if m.hi != end {
+		if b.longest(start, end, k) {
+			return true
+		}
+		m.lo, m.hi = start, end
+		return false
+	}
+	if m.lo <= start {
+		return false
+	}
+	_, w := utf8.DecodeLastRuneInString(b.s[start:m.lo])
+	if b.longest(start, m.lo-w, k) {
+		return true
+	}
+	m.lo = start
+	return false
+}
+
+// isSep reports whether r is a separator.
+func (b *backtrack) isSep(r rune) bool {
+	return runesIndexRune(b.m.sep, r) != -1
+}
+
+// end reports whether a match of the whole tree may end at s[i:], which is
+// the end of the input, or with the LeadingDir flag, a separator (or `/`,
+// when there are no separators).
+func (b *backtrack) end(i int) bool {
+	switch {
+	case i == len(b.s):
+		return true
+	case b.m.flags&LeadingDir == 0:
+		return false
+	case len(b.m.sep) == 0:
+		return b.s[i] == '/'
+	}
+	r, _ := utf8.DecodeRuneInString(b.s[i:])
+	return b.isSep(r)
+}
+
+// periodEnd returns the position of the first period after s[i] and before
+// s[end] that must be matched explicitly, or end.
+func (b *backtrack) periodEnd(i, end int) int {
+	if b.m.flags&(Period|NoDotGlob) == 0 {
+		return end
+	}
+	if b.periods == nil {
+		sep := periodSep(b.m.sep, b.m.flags)
+		b.periods = nextIndex(len(b.s), func(j int) bool {
+			return leadingPeriod(b.s, j, sep)
+		})
+	}
+	if i < len(b.s) {
+		return min(b.periods[i+1], end)
+	}
+	return end
+}
+
+// sepEnd returns the position of the first separator at or after s[i], or
+// the end of the input.
+func (b *backtrack) sepEnd(i int) int {
+	if len(b.m.sep) == 0 {
+		return len(b.s)
+	}
+	if b.seps == nil {
+		b.seps = nextIndex(len(b.s), func(j int) bool {
+			if !utf8.RuneStart(b.s[j]) {
+				return false
+			}
+			r, _ := utf8.DecodeRuneInString(b.s[j:])
+			return b.isSep(r)
+		})
+	}
+	return b.seps[i]
+}
+
+// nextIndex returns the first j >= i for which f(j) is true, or n, for each
+// position i from 0 to n.
+func nextIndex(n int, f func(int) bool) []int {
+	v := make([]int, n+1)
+	v[n] = n
+	for j := n - 1; j >= 0; j-- {
+		v[j] = v[j+1]
+		if f(j) {
+			v[j] = j
+		}
+	}
+	return v
+}
+
+// period reports whether s[i] is a period that must be matched explicitly
+// with the Period or NoDotGlob flags. Wildcards starting at such a period do not match,
+// even when empty.
+func (b *backtrack) period(i int) bool {
+	return b.m.flags&(Period|NoDotGlob) != 0 && i < len(b.s) &&
+		leadingPeriod(b.s, i, periodSep(b.m.sep, b.m.flags))
+}
+
+// single reports whether a single rune node matches r.
+func (b *backtrack) single(n *Node, r rune) bool {
+	switch n.Type {
+	case Single:
+		return !b.isSep(r)
+	case List:
+		l := n.Value.(ListData)
+		return b.fold(r, func(r rune) bool {
+			return strings.ContainsRune(l.Chars, r)
+		}) != l.Not
+	case Range:
+		v := n.Value.(RangeData)
+		return b.fold(r, func(r rune) bool {
+			return v.Lo <= r && r <= v.Hi
+		}) != v.Not
+	case Class:
+		c := n.Value.(ClassData)
+		tables := ClassTables(c.Name)
+		return b.fold(r, func(r rune) bool {
+			return unicode.IsOneOf(tables, r)
+		}) != c.Not
+	case CharSet:
+		v := n.Value.(CharSetData)
+		for _, c := range n.Children {
+			if b.single(c, r) {
+				return !v.Not
+			}
+		}
+		return v.Not
+	}
+	return false
+}
+
+// fold reports whether f is true for r, or (when folding case) for any rune
+// in the case folding orbit of r.
+func (b *backtrack) fold(r rune, f func(rune) bool) bool {
+	if f(r) {
+		return true
+	}
+	if b.m.flags&FoldCase != 0 {
+		for c := unicode.SimpleFold(r); c != r; c = unicode.SimpleFold(c) {
+			if f(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWildcard reports whether a node of the type is a wildcard term.
+func isWildcard(typ Type) bool {
+	switch typ {
+	case Any, Super, Single, List, Range, Class, CharSet, Sequence, AnyOf,
+		ZeroOrOne, ZeroOrMore, OneOrMore, ExactlyOne, Not:
+		return true
+	}
+	return false
+}
+
+// minLen returns the minimum number of runes matched by the node.
+func minLen(n *Node) int {
+	switch n.Type {
+	case Pattern:
+		var sum int
+		for _, c := range n.Children {
+			sum += minLen(c)
+		}
+		return sum
+	case AnyOf, ExactlyOne, OneOrMore:
+		v := -1
+		for _, c := range n.Children {
+			if l := minLen(c); v == -1 || l < v {
+				v = l
+			}
+		}
+		return max(v, 0)
+	case Text:
+		return utf8.RuneCountInString(n.Value.(TextData).Text)
+	case Single, List, Range, Class, CharSet:
+		return 1
+	case Sequence:
+		return n.Value.(SequenceData).minLen()
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package syntax
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBacktrack(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		sep     []rune
+		s       string
+		exp     []int
+	}{
+		{"abc", nil, "abc", []int{}},
+		{"abc", nil, "abd", nil},
+		{"a*c", nil, "abbc", []int{1, 3}},
+		{"a*c", []rune{'.'}, "ab.c", nil},
+		{"a**c", []rune{'.'}, "ab.c", []int{1, 3}},
+		{"*.*", nil, "a.b.c", []int{0, 3, 4, 5}},
+		{"?b?", nil, "abc", []int{0, 1, 2, 3}},
+		{"?b?", []rune{'a'}, "abc", nil},
+		{"[a-c][!d]", nil, "bc", []int{0, 1, 1, 2}},
+		{"[a-c][!d]", nil, "bd", nil},
+		{"x{a,b*}y", nil, "xbzzy", []int{1, 4}},
+		{"x{a,b*}y", nil, "xcy", nil},
+		{"{,a}b", nil, "b", []int{0, 0}},
+		{"ä*ö", nil, "äüüö", []int{2, 6}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexer(test.pattern))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			m := NewBacktrack(tree, test.sep, 0)
+			if v := m.Submatch(test.s); !reflect.DeepEqual(v, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, v)
+			}
+			if ok := m.Match(test.s); ok != (test.exp != nil) {
+				t.Errorf("expected %t, got: %t", test.exp != nil, ok)
+			}
+		})
+	}
+}
+
+func TestBacktrackMatchPrefix(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		sep     []rune
+		flags   Flags
+		s       string
+		exp     bool
+	}{
+		{"abc", nil, 0, "", true},
+		{"abc", nil, 0, "ab", true},
+		{"abc", nil, 0, "abc", true},
+		{"abc", nil, 0, "abd", false},
+		{"a*/b", []rune{'/'}, 0, "axx/", true},
+		{"a*/b", []rune{'/'}, 0, "axx/c", false},
+		{"a?c", nil, 0, "ax", true},
+		{"{ab,cd}e", nil, 0, "c", true},
+		{"{ab,cd}e", nil, 0, "cde", true},
+		{"{ab,cd}e", nil, 0, "ce", false},
+		{"[0-9]x", nil, 0, "a", false},
+		{"*", []rune{'/'}, 0, "a/", false},
+		{"*", []rune{'/'}, LeadingDir, "a/", true},
+		{"foo/*", []rune{'/'}, LeadingDir, "foo/bar/", true},
+		{"foo/*", []rune{'/'}, LeadingDir, "fox/", false},
+		{"foo", nil, LeadingDir, "foo/bar", true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexerFlags(test.pattern, test.flags))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := NewBacktrack(tree, test.sep, test.flags).MatchPrefix(test.s); b != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestBacktrackPathological(t *testing.T) {
+	// without memoizing failures, these take exponential time
+	s := strings.Repeat("a", 60)
+	for i, test := range []struct {
+		pattern string
+		sep     []rune
+		flags   Flags
+	}{
+		{"*a*a*a*a*a*b", nil, 0},
+		{"*a*a*a*a*a*b", []rune{'/'}, 0},
+		{"**a**a**a**a**a**b", []rune{'/'}, 0},
+		{"{*a,a*}{*a,a*}{*a,a*}{*a,a*}{*a,a*}b", nil, 0},
+		{"*(a|aa)b", nil, ExtGlob},
+		{"+(a|aa)+(a|aa)b", nil, ExtGlob},
+		{"*a*a*a*a*a*b", nil, LeadingDir},
+		{"*a*a*a*a*a*b", nil, Period},
+		{"*a*a*a*a*a*b", []rune{'/'}, Pathname | Period},
+		{"*a*a*a*a*a*b", []rune{'/'}, Pathname | LeadingDir},
+		{"*a*a*a*a*a*b", []rune{'/'}, Pathname | Period | LeadingDir},
+		{"*a*a*a*a*a*b", nil, NoDotGlob},
+		{"*a*a*a*a*a*b", []rune{'/'}, NoDotGlob},
+		{"**a**a**a**a**a**b", []rune{'/'}, NoDotGlob},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexerFlags(test.pattern, test.flags))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			m := NewBacktrack(tree, test.sep, test.flags)
+			if m.Match(s) {
+				t.Errorf("expected no match")
+			}
+			if v := m.Submatch(s); v != nil {
+				t.Errorf("expected nil, got: %v", v)
+			}
+			if v := m.Find(s, 0); v != nil {
+				t.Errorf("expected nil, got: %v", v)
+			}
+			if v := m.FindAll(s, -1); v != nil {
+				t.Errorf("expected nil, got: %v", v)
+			}
+			if v := m.Find(s+"b", 0); v == nil {
+				t.Errorf("expected match")
+			}
+			if !m.MatchPrefix(s) {
+				t.Errorf("expected prefix match")
+			}
+		})
+	}
+}
@@ -37,7 +37,7 @@ func BenchmarkRuneLenFromUTF8(b *testing.B) {
 }
 
 func BenchmarkIndexContains(b *testing.B) {
-	m := ContainsMatcher{string(bench_separators), true}
+	m := ContainsMatcher{s: string(bench_separators), not: true}
 	for b.Loop() {
 		_, s := m.Index(bench_pattern)
 		releaseSegments(s)
@@ -45,7 +45,7 @@ func BenchmarkIndexContains(b *testing.B) {
 }
 
 func BenchmarkIndexContainsParallel(b *testing.B) {
-	m := ContainsMatcher{string(bench_separators), true}
+	m := ContainsMatcher{s: string(bench_separators), not: true}
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			_, s := m.Index(bench_pattern)
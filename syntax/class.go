@@ -0,0 +1,70 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+)
+
+// classes are the POSIX character classes, with Unicode-aware semantics.
+var classes = map[string][]*unicode.RangeTable{
+	"alnum":  {unicode.Letter, unicode.Nd},
+	"alpha":  {unicode.Letter},
+	"blank":  {unicode.Zs, tab},
+	"cntrl":  {unicode.Cc},
+	"digit":  {unicode.Nd},
+	"graph":  {unicode.L, unicode.M, unicode.N, unicode.P, unicode.S},
+	"lower":  {unicode.Ll},
+	"print":  {unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Zs},
+	"punct":  {unicode.P, unicode.S},
+	"space":  {unicode.White_Space},
+	"upper":  {unicode.Lu},
+	"xdigit": {xdigit},
+}
+
+var (
+	tab = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: '\t', Hi: '\t', Stride: 1},
+		},
+		LatinOffset: 1,
+	}
+	xdigit = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: '0', Hi: '9', Stride: 1},
+			{Lo: 'A', Hi: 'F', Stride: 1},
+			{Lo: 'a', Hi: 'f', Stride: 1},
+		},
+		LatinOffset: 3,
+	}
+)
+
+// ClassTables returns the tables of the named character class, such as
+// `alpha` for `[:alpha:]`, or `p{Greek}` for the Unicode category, script or
+// property `\p{Greek}`. Returns nil when there is no such class.
+func ClassTables(name string) []*unicode.RangeTable {
+	if s, ok := strings.CutPrefix(name, "p{"); ok && strings.HasSuffix(s, "}") {
+		s = strings.TrimSuffix(s, "}")
+		for _, m := range []map[string]*unicode.RangeTable{
+			unicode.Categories,
+			unicode.Scripts,
+			unicode.Properties,
+		} {
+			if t, ok := m[s]; ok {
+				return []*unicode.RangeTable{t}
+			}
+		}
+		return nil
+	}
+	return classes[name]
+}
+
+// classString returns the bracket expression syntax for the class.
+func classString(d ClassData) string {
+	switch {
+	case !strings.HasPrefix(d.Name, "p{"):
+		return "[:" + d.Name + ":]"
+	case d.Not:
+		return `\P` + d.Name[1:]
+	}
+	return `\` + d.Name
+}
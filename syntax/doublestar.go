@@ -0,0 +1,53 @@
+package syntax
+
+import (
+	"strings"
+)
+
+// doubleStar rewrites the super wildcards (`**`) of the node for the
+// DoubleStar flag, where start and end report whether the node starts and
+// ends at a segment boundary. A `**` that is not a whole segment is rewritten
+// to `*`, and a `**/` to `{,**/}`, matching zero or more whole segments.
+func doubleStar(n *Node, start, end bool) {
+	switch n.Type {
+	case Pattern:
+	case AnyOf, ExactlyOne, ZeroOrOne, ZeroOrMore, OneOrMore, Not:
+		for _, c := range n.Children {
+			doubleStar(c, start, end)
+		}
+		return
+	default:
+		return
+	}
+	var children []*Node
+	for i := 0; i < len(n.Children); i++ {
+		c, last := n.Children[i], i == len(n.Children)-1
+		first := i == 0 && start || i != 0 && hasSlash(n.Children[i-1], strings.HasSuffix)
+		final := last && end || !last && hasSlash(n.Children[i+1], strings.HasPrefix)
+		switch {
+		case c.Type != Super:
+			doubleStar(c, first, final)
+		case !first || !final:
+			c = New(Any, nil)
+		case !last:
+			c = New(AnyOf, nil,
+				New(Pattern, nil),
+				New(Pattern, nil, New(Super, nil), New(Text, TextData{"/"})),
+			)
+			if s := n.Children[i+1].Value.(TextData).Text[1:]; s != "" {
+				n.Children[i+1] = New(Text, TextData{s})
+			} else {
+				i++
+			}
+		}
+		children = append(children, c)
+	}
+	n.Children = nil
+	n.Insert(children...)
+}
+
+// hasSlash reports whether the node is text that has a `/` prefix or suffix,
+// using f.
+func hasSlash(n *Node, f func(string, string) bool) bool {
+	return n.Type == Text && f(n.Value.(TextData).Text, "/")
+}
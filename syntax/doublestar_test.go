@@ -0,0 +1,43 @@
+package syntax
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDoubleStar(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		exp     string
+	}{
+		{`**`, `**`},
+		{`a/**`, `a/**`},
+		{`**/b`, `{,**/}b`},
+		{`a/**/b`, `a/{,**/}b`},
+		{`a/**/**/b`, `a/{,**/}{,**/}b`},
+		{`a/**/`, `a/{,**/}`},
+		{`/**/`, `/{,**/}`},
+		{`a**b`, `a*b`},
+		{`a/**b`, `a/*b`},
+		{`a**/b`, `a*/b`},
+		{`**.go`, `*.go`},
+		{`{a,b}/**/c`, `{a,b}/{,**/}c`},
+		{`{**/a,b**}`, `{{,**/}a,b*}`},
+		{`x{**/a,b}`, `x{*/a,b}`},
+		{`a/{**,b}/c`, `a/{**,b}/c`},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexerFlags(test.pattern, DoubleStar))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			exp, err := Parse(NewLexer(test.exp))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if a, b := tree.String(), exp.String(); a != b {
+				t.Errorf("expected %s, got: %s", b, a)
+			}
+		})
+	}
+}
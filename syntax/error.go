@@ -0,0 +1,86 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is the kind of a syntax error.
+type ErrorCode string
+
+// Error codes.
+const (
+	ErrInternal          ErrorCode = "internal error"
+	ErrInvalidUTF8       ErrorCode = "invalid UTF-8"
+	ErrMissingBracket    ErrorCode = "missing closing ]"
+	ErrMissingBrace      ErrorCode = "missing closing }"
+	ErrMissingParen      ErrorCode = "missing closing )"
+	ErrUnexpectedBrace   ErrorCode = "unexpected }"
+	ErrUnexpectedBracket ErrorCode = "unexpected ]"
+	ErrEmptyAlt          ErrorCode = "empty alternative"
+	ErrTrailingEscape    ErrorCode = "trailing backslash"
+	ErrInvalidRange      ErrorCode = "invalid character range"
+	ErrInvalidClass      ErrorCode = "invalid character class"
+	ErrEmptyClass        ErrorCode = "empty character class"
+	ErrInvalidSequence   ErrorCode = "invalid sequence"
+	ErrUnexpectedToken   ErrorCode = "unexpected token"
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (code ErrorCode) String() string {
+	return string(code)
+}
+
+// Error is a syntax error in a pattern.
+type Error struct {
+	// Pattern is the pattern.
+	Pattern string
+	// Offset is the byte offset of the error in the pattern.
+	Offset int
+	// Code is the kind of error.
+	Code ErrorCode
+	// Msg describes the error.
+	Msg string
+}
+
+// Error satisfies the [error] interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+// Caret returns the pattern followed by a line with a `^` under the character
+// at the offset of the error:
+//
+//	a[z-a]b
+//	 ^
+func (e *Error) Caret() string {
+	return Caret(e.Pattern, e.Offset)
+}
+
+// Caret returns s followed by a line with a `^` under the character at the
+// byte offset i of s. Tabs in s before the offset are kept, so that the caret
+// lines up when the lines are displayed.
+func Caret(s string, i int) string {
+	i = max(0, min(i, len(s)))
+	var b strings.Builder
+	b.WriteString(s)
+	b.WriteByte('\n')
+	for _, r := range s[:i] {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	return b.String()
+}
+
+// errorf creates an error at the offset of the token.
+func errorf(token Token, code ErrorCode, format string, args ...any) *Error {
+	return &Error{
+		Offset: token.Offset,
+		Code:   code,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,39 @@
+package syntax
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestErrorCaret(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		offset  int
+		exp     string
+	}{
+		{"", 0, "\n^"},
+		{"[z-a]", 1, "[z-a]\n ^"},
+		{"a[bc", 1, "a[bc\n ^"},
+		{"abc", 3, "abc\n   ^"},
+		{"日本[語", 6, "日本[語\n  ^"},
+		{"\ta[", 2, "\ta[\n\t ^"},
+		{"abc", 10, "abc\n   ^"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			e := &Error{Pattern: test.pattern, Offset: test.offset}
+			if s := e.Caret(); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
+
+func TestErrorError(t *testing.T) {
+	_, err := Parse(NewLexer("a[z-a]"))
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if exp := "syntax error at offset 2: hi character 'a' should be greater than lo 'z'"; err.Error() != exp {
+		t.Errorf("expected %q, got: %q", exp, err.Error())
+	}
+}
@@ -0,0 +1,39 @@
+package syntax
+
+// Flags control how patterns are lexed, parsed and built into matchers.
+type Flags uint
+
+const (
+	// FoldCase matches text case-insensitively, using Unicode simple case
+	// folding.
+	FoldCase Flags = 1 << iota
+	// NoEscape treats `\` as a regular character rather than an escape.
+	NoEscape
+	// ExtGlob enables the extended pattern lists `?(...)`, `*(...)`,
+	// `+(...)`, `@(...)` and `!(...)`, as with bash's extglob option.
+	ExtGlob
+	// Pathname stops bracket expressions from matching separators, as with
+	// fnmatch's FNM_PATHNAME.
+	Pathname
+	// Period stops wildcards and bracket expressions from matching a leading
+	// period, or a period following a separator, as with fnmatch's
+	// FNM_PERIOD. Such periods must be matched by a period in the pattern.
+	Period
+	// LeadingDir matches when the pattern matches the input up to a separator
+	// (or `/`, when there are no separators), ignoring the rest of the input,
+	// as with fnmatch's FNM_LEADING_DIR.
+	LeadingDir
+	// NoDotGlob is Period, where a period following a `/` is also leading
+	// when there are no separators, as with bash when its dotglob option is
+	// unset. Wildcards do not match hidden names in any path segment, while
+	// an explicit `.*` does.
+	NoDotGlob
+	// DoubleStar makes `**` only special as a whole path segment, as with
+	// gitignore: `a/**/b` matches `a/b` and `a/x/y/b`, and a trailing `/**`
+	// matches everything below a directory. Elsewhere, `**` is `*`.
+	DoubleStar
+	// Strict rejects patterns with problems that are otherwise tolerated: an
+	// unterminated `{` or extended pattern list, a stray `}` or `]`, a
+	// trailing `\` and an empty alternative, such as in `{a,}`.
+	Strict
+)
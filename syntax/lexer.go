@@ -2,8 +2,8 @@ package syntax
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -26,6 +26,10 @@ const (
 	TokenRangeBetween
 	TokenTermsOpen
 	TokenTermsClose
+	TokenExtOpen
+	TokenExtClose
+	TokenRangeClass
+	TokenSequence
 )
 
 func (typ TokenType) String() string {
@@ -62,6 +66,14 @@ func (typ TokenType) String() string {
 		return "terms_open"
 	case TokenTermsClose:
 		return "terms_close"
+	case TokenExtOpen:
+		return "ext_open"
+	case TokenExtClose:
+		return "ext_close"
+	case TokenRangeClass:
+		return "range_class"
+	case TokenSequence:
+		return "sequence"
 	}
 	return "undef"
 }
@@ -69,6 +81,8 @@ func (typ TokenType) String() string {
 type Token struct {
 	Token TokenType
 	Raw   string
+	// Offset is the byte offset of the start of the token in the pattern.
+	Offset int
 }
 
 func (token Token) String() string {
@@ -95,12 +109,15 @@ func (v *tokens) empty() bool {
 type Lexer struct {
 	src          string
 	pos          int
-	err          error
+	err          *Error
+	errs         []*Error
+	recover      bool
 	tokens       tokens
-	termsLevel   int
+	groups       []group
 	lastRune     rune
 	lastRuneSize int
 	hasRune      bool
+	flags        Flags
 }
 
 func NewLexer(src string) *Lexer {
@@ -111,9 +128,16 @@ func NewLexer(src string) *Lexer {
 	return l
 }
 
+// NewLexerFlags creates a lexer for src using the flags.
+func NewLexerFlags(src string, flags Flags) *Lexer {
+	l := NewLexer(src)
+	l.flags = flags
+	return l
+}
+
 func (l *Lexer) Next() Token {
 	if l.err != nil {
-		return Token{TokenError, l.err.Error()}
+		return Token{TokenError, l.err.Msg, l.err.Offset}
 	}
 	if !l.tokens.empty() {
 		return l.tokens.shift()
@@ -128,8 +152,10 @@ func (l *Lexer) peek() (r rune, w int) {
 	}
 	r, w = utf8.DecodeRuneInString(l.src[l.pos:])
 	if r == utf8.RuneError {
-		l.errorf("could not read rune")
-		r, w = 0, 0
+		l.errorf(l.pos, ErrInvalidUTF8, "invalid UTF-8")
+		if !l.recover {
+			r, w = 0, 0
+		}
 	}
 	return
 }
@@ -153,60 +179,139 @@ func (l *Lexer) seek(w int) {
 
 func (l *Lexer) unread() {
 	if l.hasRune {
-		l.errorf("could not unread rune")
+		l.errorf(l.pos, ErrInternal, "could not unread rune")
 		return
 	}
 	l.seek(-l.lastRuneSize)
 	l.hasRune = true
 }
 
-func (l *Lexer) errorf(s string) {
-	l.err = errors.New(s)
+// errorf records an error at the byte offset pos of the source, unless an
+// error was already recorded. When recovering, the error is added to the
+// recovered errors instead, and lexing continues.
+func (l *Lexer) errorf(pos int, code ErrorCode, format string, args ...any) {
+	e := &Error{
+		Pattern: l.src,
+		Offset:  pos,
+		Code:    code,
+		Msg:     fmt.Sprintf(format, args...),
+	}
+	switch {
+	case l.recover:
+		for _, err := range l.errs {
+			if err.Offset == e.Offset && err.Code == e.Code {
+				return
+			}
+		}
+		l.errs = append(l.errs, e)
+	case l.err == nil:
+		l.err = e
+	}
+}
+
+// problemf records a problem tolerated by the lexer at the byte offset pos of
+// the source. Problems are only reported when recovering, or as errors with the
+// Strict flag.
+func (l *Lexer) problemf(pos int, code ErrorCode, format string, args ...any) {
+	if l.recover || l.flags&Strict != 0 {
+		l.errorf(pos, code, format, args...)
+	}
+}
+
+// group is a `{` or extended pattern list opened at the byte offset pos of the
+// source.
+type group struct {
+	r   rune
+	pos int
 }
 
 func (l *Lexer) inTerms() bool {
-	return l.termsLevel > 0
+	return len(l.groups) > 0 && l.groups[len(l.groups)-1].r == char_terms_open
 }
 
-func (l *Lexer) termsEnter() {
-	l.termsLevel++
+func (l *Lexer) termsEnter(pos int) {
+	l.groups = append(l.groups, group{char_terms_open, pos})
 }
 
 func (l *Lexer) termsLeave() {
-	l.termsLevel--
+	l.groups = l.groups[:len(l.groups)-1]
+}
+
+// inExt reports whether the lexer is inside an extended pattern list.
+func (l *Lexer) inExt() bool {
+	return len(l.groups) > 0 && l.groups[len(l.groups)-1].r == char_ext_open
+}
+
+// extOpen reports whether r, which was just read, opens an extended pattern
+// list.
+func (l *Lexer) extOpen(r rune) bool {
+	return l.flags&ExtGlob != 0 &&
+		strings.ContainsRune(extOps, r) &&
+		strings.HasPrefix(l.src[l.pos:], string(char_ext_open))
+}
+
+// fetchExtOpen fetches the opening of an extended pattern list for the
+// operator r.
+func (l *Lexer) fetchExtOpen(r rune) {
+	l.seek(1)
+	l.groups = append(l.groups, group{char_ext_open, l.pos - 2})
+	l.tokens.push(Token{TokenExtOpen, string(r) + string(char_ext_open), l.pos - 2})
 }
 
 func (l *Lexer) fetchItem() {
+	start := l.pos
 	r := l.read()
 	switch {
 	case r == 0:
-		l.tokens.push(Token{TokenEOF, ""})
+		for i := len(l.groups) - 1; i >= 0; i-- {
+			if g := l.groups[i]; g.r == char_terms_open {
+				l.problemf(g.pos, ErrMissingBrace, "missing closing %c", char_terms_close)
+			} else {
+				l.problemf(g.pos, ErrMissingParen, "missing closing %c", char_ext_close)
+			}
+		}
+		l.tokens.push(Token{TokenEOF, "", start})
+	case r == char_terms_open && l.fetchSequence(start):
 	case r == char_terms_open:
-		l.termsEnter()
-		l.tokens.push(Token{TokenTermsOpen, string(r)})
+		l.termsEnter(start)
+		l.tokens.push(Token{TokenTermsOpen, string(r), start})
 	case r == char_comma && l.inTerms():
-		l.tokens.push(Token{TokenSeparator, string(r)})
+		l.tokens.push(Token{TokenSeparator, string(r), start})
 	case r == char_terms_close && l.inTerms():
-		l.tokens.push(Token{TokenTermsClose, string(r)})
+		l.tokens.push(Token{TokenTermsClose, string(r), start})
 		l.termsLeave()
+	case l.extOpen(r):
+		l.fetchExtOpen(r)
+	case r == char_ext_separator && l.inExt():
+		l.tokens.push(Token{TokenSeparator, string(r), start})
+	case r == char_ext_close && l.inExt():
+		l.tokens.push(Token{TokenExtClose, string(r), start})
+		l.groups = l.groups[:len(l.groups)-1]
 	case r == char_range_open:
-		l.tokens.push(Token{TokenRangeOpen, string(r)})
+		l.tokens.push(Token{TokenRangeOpen, string(r), start})
 		l.fetchRange()
 	case r == char_single:
-		l.tokens.push(Token{TokenSingle, string(r)})
+		l.tokens.push(Token{TokenSingle, string(r), start})
 	case r == charAny:
-		if l.read() == charAny {
-			l.tokens.push(Token{TokenSuper, string(r) + string(r)})
-		} else {
+		switch {
+		case l.read() != charAny:
 			l.unread()
-			l.tokens.push(Token{TokenAny, string(r)})
+			l.tokens.push(Token{TokenAny, string(r), start})
+		case l.extOpen(r):
+			l.tokens.push(Token{TokenAny, string(r), start})
+			l.fetchExtOpen(r)
+		default:
+			l.tokens.push(Token{TokenSuper, string(r) + string(r), start})
 		}
 	default:
 		l.unread()
 		var breakers []rune
-		if l.inTerms() {
+		switch {
+		case l.inTerms():
 			breakers = inTermsBreakers
-		} else {
+		case l.inExt():
+			breakers = inExtBreakers
+		default:
 			breakers = inTextBreakers
 		}
 		l.fetchText(breakers)
@@ -214,70 +319,195 @@ func (l *Lexer) fetchItem() {
 }
 
 func (l *Lexer) fetchRange() {
-	var wantHi bool
-	var wantClose bool
-	var seenNot bool
-	for {
+	open := l.pos - 1
+	var (
+		data []rune
+		at   int
+	)
+	flush := func() {
+		if len(data) > 0 {
+			l.tokens.push(Token{TokenText, string(data), at})
+			data = nil
+		}
+	}
+	for first := true; ; first = false {
+		pos := l.pos
 		r := l.read()
-		if r == 0 {
-			l.errorf("unexpected end of input")
+		switch {
+		case r == 0:
+			l.unclosedRange(open)
 			return
-		}
-		if wantClose {
-			if r != char_range_close {
-				l.errorf("expected close range character")
-			} else {
-				l.tokens.push(Token{TokenRangeClose, string(r)})
-			}
+		case r == char_range_close:
+			flush()
+			l.tokens.push(Token{TokenRangeClose, string(r), pos})
 			return
-		}
-		if wantHi {
-			l.tokens.push(Token{TokenRangeHi, string(r)})
-			wantClose = true
+		case first && r == char_range_not:
+			l.tokens.push(Token{TokenNot, string(r), pos})
 			continue
-		}
-		if !seenNot && r == char_range_not {
-			l.tokens.push(Token{TokenNot, string(r)})
-			seenNot = true
+		case r == char_range_open && l.hasClass():
+			flush()
+			l.fetchClass(pos)
+			continue
+		case r == char_escape && l.flags&NoEscape == 0 && l.hasProperty():
+			flush()
+			l.fetchProperty(pos)
 			continue
 		}
-		if n, w := l.peek(); n == char_range_between {
-			l.seek(w)
-			l.tokens.push(Token{TokenRangeLo, string(r)})
-			l.tokens.push(Token{TokenRangeBetween, string(n)})
-			wantHi = true
+		r, ok := l.rangeChar(r, pos, open)
+		if !ok {
+			return
+		}
+		// a `-` before the closing `]` is a regular character
+		n, w := l.peek()
+		if n != char_range_between || strings.HasPrefix(l.src[l.pos+w:], string(char_range_close)) {
+			if len(data) == 0 {
+				at = pos
+			}
+			data = append(data, r)
 			continue
 		}
-		l.unread() // unread first peek and fetch as text
-		l.fetchText([]rune{char_range_close})
-		wantClose = true
+		flush()
+		between := l.pos
+		l.seek(w)
+		hiPos := l.pos
+		hi, ok := l.rangeChar(l.read(), hiPos, open)
+		if !ok {
+			return
+		}
+		l.tokens.push(Token{TokenRangeLo, string(r), pos})
+		l.tokens.push(Token{TokenRangeBetween, string(char_range_between), between})
+		l.tokens.push(Token{TokenRangeHi, string(hi), hiPos})
+	}
+}
+
+// fetchSequence fetches a brace sequence (`{x..y}` or `{x..y..incr}`)
+// following the `{` just read at the byte offset start. Returns false when
+// there is no sequence.
+func (l *Lexer) fetchSequence(start int) bool {
+	s := l.src[l.pos:]
+	i := strings.IndexRune(s, char_terms_close)
+	if i == -1 {
+		return false
+	}
+	if _, ok := parseSequence(s[:i]); !ok {
+		return false
+	}
+	l.tokens.push(Token{TokenSequence, s[:i], start})
+	l.seek(i + 1)
+	return true
+}
+
+// hasProperty reports whether a Unicode property class (`\p{name}`,
+// `\P{name}` or the single letter forms `\pN` and `\PN`) follows the `\`
+// just read.
+func (l *Lexer) hasProperty() bool {
+	s := l.src[l.pos:]
+	switch {
+	case len(s) < 2 || s[0] != 'p' && s[0] != 'P':
+		return false
+	case s[1] == char_terms_open:
+		return strings.IndexByte(s, char_terms_close) > 2
+	}
+	return 'A' <= s[1] && s[1] <= 'Z'
+}
+
+// fetchProperty fetches a Unicode property class following the `\` just
+// read at the byte offset start. The token is the class in its long form
+// (`p{name}` or `P{name}`).
+func (l *Lexer) fetchProperty(start int) {
+	s := l.src[l.pos:]
+	name, n := s[1:2], 2
+	if s[1] == char_terms_open {
+		i := strings.IndexByte(s, char_terms_close)
+		name, n = s[2:i], i+1
 	}
+	l.tokens.push(Token{TokenRangeClass, s[:1] + "{" + name + "}", start})
+	l.seek(n)
+}
+
+// rangeChar returns the character of a bracket expression for r, which was
+// just read at the byte offset pos, reading the escaped character when r is
+// an escape. The bracket expression was opened at the byte offset open.
+func (l *Lexer) rangeChar(r rune, pos, open int) (rune, bool) {
+	escaped := r == char_escape && l.flags&NoEscape == 0
+	if escaped {
+		r = l.read()
+	}
+	switch {
+	case r == 0 && escaped:
+		l.errorf(pos, ErrTrailingEscape, "trailing %c", char_escape)
+		l.unclosedRange(open)
+		return 0, false
+	case r == 0:
+		l.unclosedRange(open)
+		return 0, false
+	}
+	return r, true
+}
+
+// unclosedRange records an error for the bracket expression opened at the byte
+// offset open, which was not closed. When recovering, the `[` is lexed again
+// as text, as with bash.
+func (l *Lexer) unclosedRange(open int) {
+	l.errorf(open, ErrMissingBracket, "missing closing %c", char_range_close)
+	if l.recover {
+		l.tokens = l.tokens[:0]
+		l.tokens.push(Token{TokenText, string(char_range_open), open})
+		l.pos, l.hasRune = open+1, false
+	}
+}
+
+// hasClass reports whether a named character class (`[:name:]`) follows the
+// `[` just read.
+func (l *Lexer) hasClass() bool {
+	s := l.src[l.pos:]
+	return strings.HasPrefix(s, string(char_class)) &&
+		strings.Contains(s[1:], string(char_class)+string(char_range_close))
+}
+
+// fetchClass fetches a named character class (`[:name:]`) following the `[`
+// just read at the byte offset start.
+func (l *Lexer) fetchClass(start int) {
+	s := l.src[l.pos:]
+	i := strings.Index(s[1:], string(char_class)+string(char_range_close))
+	l.tokens.push(Token{TokenRangeClass, s[1 : i+1], start})
+	l.seek(i + 3)
 }
 
 func (l *Lexer) fetchText(breakers []rune) {
+	start := l.pos
 	var data []rune
 	var escaped bool
 loop:
 	for {
 		r := l.read()
 		if r == 0 {
+			if escaped {
+				l.problemf(l.pos-1, ErrTrailingEscape, "trailing %c", char_escape)
+			}
 			break
 		}
 		if !escaped {
-			if r == char_escape {
+			if r == char_escape && l.flags&NoEscape == 0 {
 				escaped = true
 				continue
 			}
-			if runesIndexRune(breakers, r) != -1 {
+			if runesIndexRune(breakers, r) != -1 || l.extOpen(r) {
 				l.unread()
 				break loop
 			}
+			switch {
+			case r == char_terms_close:
+				l.problemf(l.pos-1, ErrUnexpectedBrace, "unexpected %c", r)
+			case r == char_range_close:
+				l.problemf(l.pos-1, ErrUnexpectedBracket, "unexpected %c", r)
+			}
 		}
 		escaped = false
 		data = append(data, r)
 	}
 	if len(data) > 0 {
-		l.tokens.push(Token{TokenText, string(data)})
+		l.tokens.push(Token{TokenText, string(data), start})
 	}
 }
 
@@ -296,8 +526,15 @@ const (
 	char_terms_close   = '}'
 	char_range_not     = '!'
 	char_range_between = '-'
+	char_ext_open      = '('
+	char_ext_close     = ')'
+	char_ext_separator = '|'
+	char_class         = ':'
 )
 
+// extOps are the operators of extended pattern lists.
+const extOps = "?*+@!"
+
 var specials = []byte{
 	charAny,
 	char_single,
@@ -306,9 +543,11 @@ var specials = []byte{
 	char_range_close,
 	char_terms_open,
 	char_terms_close,
+	char_ext_open,
 }
 
 var (
 	inTextBreakers  = []rune{char_single, charAny, char_range_open, char_terms_open}
 	inTermsBreakers = append(inTextBreakers, char_terms_close, char_comma)
+	inExtBreakers   = append(inTextBreakers, char_ext_close, char_ext_separator)
 )
@@ -7,178 +7,285 @@ import (
 func TestLexer(t *testing.T) {
 	for id, test := range []struct {
 		pattern string
+		flags   Flags
 		items   []Token
 	}{
 		{
 			pattern: "",
 			items: []Token{
-				{TokenEOF, ""},
+				{TokenEOF, "", 0},
 			},
 		},
 		{
 			pattern: "hello",
 			items: []Token{
-				{TokenText, "hello"},
-				{TokenEOF, ""},
+				{TokenText, "hello", 0},
+				{TokenEOF, "", 5},
 			},
 		},
 		{
 			pattern: "/{rate,[0-9]]}*",
 			items: []Token{
-				{TokenText, "/"},
-				{TokenTermsOpen, "{"},
-				{TokenText, "rate"},
-				{TokenSeparator, ","},
-				{TokenRangeOpen, "["},
-				{TokenRangeLo, "0"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "9"},
-				{TokenRangeClose, "]"},
-				{TokenText, "]"},
-				{TokenTermsClose, "}"},
-				{TokenAny, "*"},
-				{TokenEOF, ""},
+				{TokenText, "/", 0},
+				{TokenTermsOpen, "{", 1},
+				{TokenText, "rate", 2},
+				{TokenSeparator, ",", 6},
+				{TokenRangeOpen, "[", 7},
+				{TokenRangeLo, "0", 8},
+				{TokenRangeBetween, "-", 9},
+				{TokenRangeHi, "9", 10},
+				{TokenRangeClose, "]", 11},
+				{TokenText, "]", 12},
+				{TokenTermsClose, "}", 13},
+				{TokenAny, "*", 14},
+				{TokenEOF, "", 15},
 			},
 		},
 		{
 			pattern: "hello,world",
 			items: []Token{
-				{TokenText, "hello,world"},
-				{TokenEOF, ""},
+				{TokenText, "hello,world", 0},
+				{TokenEOF, "", 11},
 			},
 		},
 		{
 			pattern: "hello\\,world",
 			items: []Token{
-				{TokenText, "hello,world"},
-				{TokenEOF, ""},
+				{TokenText, "hello,world", 0},
+				{TokenEOF, "", 12},
 			},
 		},
 		{
 			pattern: "hello\\{world",
 			items: []Token{
-				{TokenText, "hello{world"},
-				{TokenEOF, ""},
+				{TokenText, "hello{world", 0},
+				{TokenEOF, "", 12},
 			},
 		},
 		{
 			pattern: "hello?",
 			items: []Token{
-				{TokenText, "hello"},
-				{TokenSingle, "?"},
-				{TokenEOF, ""},
+				{TokenText, "hello", 0},
+				{TokenSingle, "?", 5},
+				{TokenEOF, "", 6},
 			},
 		},
 		{
 			pattern: "hellof*",
 			items: []Token{
-				{TokenText, "hellof"},
-				{TokenAny, "*"},
-				{TokenEOF, ""},
+				{TokenText, "hellof", 0},
+				{TokenAny, "*", 6},
+				{TokenEOF, "", 7},
 			},
 		},
 		{
 			pattern: "hello**",
 			items: []Token{
-				{TokenText, "hello"},
-				{TokenSuper, "**"},
-				{TokenEOF, ""},
+				{TokenText, "hello", 0},
+				{TokenSuper, "**", 5},
+				{TokenEOF, "", 7},
 			},
 		},
 		{
 			pattern: "[日-語]",
 			items: []Token{
-				{TokenRangeOpen, "["},
-				{TokenRangeLo, "日"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "語"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenRangeLo, "日", 1},
+				{TokenRangeBetween, "-", 4},
+				{TokenRangeHi, "語", 5},
+				{TokenRangeClose, "]", 8},
+				{TokenEOF, "", 9},
 			},
 		},
 		{
 			pattern: "[!日-語]",
 			items: []Token{
-				{TokenRangeOpen, "["},
-				{TokenNot, "!"},
-				{TokenRangeLo, "日"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "語"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenRangeLo, "日", 2},
+				{TokenRangeBetween, "-", 5},
+				{TokenRangeHi, "語", 6},
+				{TokenRangeClose, "]", 9},
+				{TokenEOF, "", 10},
 			},
 		},
 		{
 			pattern: "[日本語]",
 			items: []Token{
-				{TokenRangeOpen, "["},
-				{TokenText, "日本語"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenText, "日本語", 1},
+				{TokenRangeClose, "]", 10},
+				{TokenEOF, "", 11},
 			},
 		},
 		{
 			pattern: "[!日本語]",
 			items: []Token{
-				{TokenRangeOpen, "["},
-				{TokenNot, "!"},
-				{TokenText, "日本語"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenText, "日本語", 2},
+				{TokenRangeClose, "]", 11},
+				{TokenEOF, "", 12},
 			},
 		},
 		{
 			pattern: "{a,b}",
 			items: []Token{
-				{TokenTermsOpen, "{"},
-				{TokenText, "a"},
-				{TokenSeparator, ","},
-				{TokenText, "b"},
-				{TokenTermsClose, "}"},
-				{TokenEOF, ""},
+				{TokenTermsOpen, "{", 0},
+				{TokenText, "a", 1},
+				{TokenSeparator, ",", 2},
+				{TokenText, "b", 3},
+				{TokenTermsClose, "}", 4},
+				{TokenEOF, "", 5},
 			},
 		},
 		{
 			pattern: "/{z,ab}*",
 			items: []Token{
-				{TokenText, "/"},
-				{TokenTermsOpen, "{"},
-				{TokenText, "z"},
-				{TokenSeparator, ","},
-				{TokenText, "ab"},
-				{TokenTermsClose, "}"},
-				{TokenAny, "*"},
-				{TokenEOF, ""},
+				{TokenText, "/", 0},
+				{TokenTermsOpen, "{", 1},
+				{TokenText, "z", 2},
+				{TokenSeparator, ",", 3},
+				{TokenText, "ab", 4},
+				{TokenTermsClose, "}", 6},
+				{TokenAny, "*", 7},
+				{TokenEOF, "", 8},
 			},
 		},
 		{
 			pattern: "{[!日-語],*,?,{a,b,\\c}}",
 			items: []Token{
-				{TokenTermsOpen, "{"},
-				{TokenRangeOpen, "["},
-				{TokenNot, "!"},
-				{TokenRangeLo, "日"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "語"},
-				{TokenRangeClose, "]"},
-				{TokenSeparator, ","},
-				{TokenAny, "*"},
-				{TokenSeparator, ","},
-				{TokenSingle, "?"},
-				{TokenSeparator, ","},
-				{TokenTermsOpen, "{"},
-				{TokenText, "a"},
-				{TokenSeparator, ","},
-				{TokenText, "b"},
-				{TokenSeparator, ","},
-				{TokenText, "c"},
-				{TokenTermsClose, "}"},
-				{TokenTermsClose, "}"},
-				{TokenEOF, ""},
+				{TokenTermsOpen, "{", 0},
+				{TokenRangeOpen, "[", 1},
+				{TokenNot, "!", 2},
+				{TokenRangeLo, "日", 3},
+				{TokenRangeBetween, "-", 6},
+				{TokenRangeHi, "語", 7},
+				{TokenRangeClose, "]", 10},
+				{TokenSeparator, ",", 11},
+				{TokenAny, "*", 12},
+				{TokenSeparator, ",", 13},
+				{TokenSingle, "?", 14},
+				{TokenSeparator, ",", 15},
+				{TokenTermsOpen, "{", 16},
+				{TokenText, "a", 17},
+				{TokenSeparator, ",", 18},
+				{TokenText, "b", 19},
+				{TokenSeparator, ",", 20},
+				{TokenText, "c", 21},
+				{TokenTermsClose, "}", 23},
+				{TokenTermsClose, "}", 24},
+				{TokenEOF, "", 25},
+			},
+		},
+		{
+			pattern: "a\\*\\{b,c}",
+			flags:   NoEscape,
+			items: []Token{
+				{TokenText, "a\\", 0},
+				{TokenAny, "*", 2},
+				{TokenText, "\\", 3},
+				{TokenTermsOpen, "{", 4},
+				{TokenText, "b", 5},
+				{TokenSeparator, ",", 6},
+				{TokenText, "c", 7},
+				{TokenTermsClose, "}", 8},
+				{TokenEOF, "", 9},
+			},
+		},
+		{
+			pattern: "a+(b|c)!(d{e,f})**(g)",
+			flags:   ExtGlob,
+			items: []Token{
+				{TokenText, "a", 0},
+				{TokenExtOpen, "+(", 1},
+				{TokenText, "b", 3},
+				{TokenSeparator, "|", 4},
+				{TokenText, "c", 5},
+				{TokenExtClose, ")", 6},
+				{TokenExtOpen, "!(", 7},
+				{TokenText, "d", 9},
+				{TokenTermsOpen, "{", 10},
+				{TokenText, "e", 11},
+				{TokenSeparator, ",", 12},
+				{TokenText, "f", 13},
+				{TokenTermsClose, "}", 14},
+				{TokenExtClose, ")", 15},
+				{TokenAny, "*", 16},
+				{TokenExtOpen, "*(", 17},
+				{TokenText, "g", 19},
+				{TokenExtClose, ")", 20},
+				{TokenEOF, "", 21},
+			},
+		},
+		{
+			pattern: "a+(b|c)",
+			items: []Token{
+				{TokenText, "a+(b|c)", 0},
+				{TokenEOF, "", 7},
+			},
+		},
+		{
+			pattern: "[[:alpha:]][![:digit:]][[:bogus]",
+			items: []Token{
+				{TokenRangeOpen, "[", 0},
+				{TokenRangeClass, "alpha", 1},
+				{TokenRangeClose, "]", 10},
+				{TokenRangeOpen, "[", 11},
+				{TokenNot, "!", 12},
+				{TokenRangeClass, "digit", 13},
+				{TokenRangeClose, "]", 22},
+				{TokenRangeOpen, "[", 23},
+				{TokenText, "[:bogus", 24},
+				{TokenRangeClose, "]", 31},
+				{TokenEOF, "", 32},
+			},
+		},
+		{
+			pattern: `[!a-zA-Z_\]\-[:digit:]-]`,
+			items: []Token{
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenRangeLo, "a", 2},
+				{TokenRangeBetween, "-", 3},
+				{TokenRangeHi, "z", 4},
+				{TokenRangeLo, "A", 5},
+				{TokenRangeBetween, "-", 6},
+				{TokenRangeHi, "Z", 7},
+				{TokenText, "_]-", 8},
+				{TokenRangeClass, "digit", 13},
+				{TokenText, "-", 22},
+				{TokenRangeClose, "]", 23},
+				{TokenEOF, "", 24},
+			},
+		},
+		{
+			pattern: "log.{1..30}.{a..c..2}{1..x}",
+			items: []Token{
+				{TokenText, "log.", 0},
+				{TokenSequence, "1..30", 4},
+				{TokenText, ".", 11},
+				{TokenSequence, "a..c..2", 12},
+				{TokenTermsOpen, "{", 21},
+				{TokenText, "1..x", 22},
+				{TokenTermsClose, "}", 26},
+				{TokenEOF, "", 27},
+			},
+		},
+		{
+			pattern: `[a\p{Han}\PL\pa]`,
+			items: []Token{
+				{TokenRangeOpen, "[", 0},
+				{TokenText, "a", 1},
+				{TokenRangeClass, "p{Han}", 2},
+				{TokenRangeClass, "P{L}", 9},
+				{TokenText, "pa", 12},
+				{TokenRangeClose, "]", 15},
+				{TokenEOF, "", 16},
 			},
 		},
 	} {
-		lexer := NewLexer(test.pattern)
+		lexer := NewLexerFlags(test.pattern, test.flags)
 		for i, exp := range test.items {
 			token := lexer.Next()
 			if token.Token != exp.Token {
@@ -187,6 +294,9 @@ func TestLexer(t *testing.T) {
 			if token.Raw != exp.Raw {
 				t.Errorf("#%d %q: wrong %d-th item contents: exp: %q; act: %q\n\t(%s vs %s)", id, test.pattern, i, exp.Raw, token.Raw, exp, token)
 			}
+			if token.Offset != exp.Offset {
+				t.Errorf("#%d %q: wrong %d-th item offset: exp: %d; act: %d\n\t(%s vs %s)", id, test.pattern, i, exp.Offset, token.Offset, exp, token)
+			}
 		}
 	}
 }
@@ -164,7 +164,7 @@ func Optimize(m Matcher) (opt Matcher) {
 	switch v := m.(type) {
 	case AnyMatcher:
 		if len(v.sep) == 0 {
-			return NewSuper()
+			return SuperMatcher{period: v.period}
 		}
 	case ListMatcher:
 		if v.not == false && len(v.rs) == 1 {
@@ -183,7 +183,7 @@ func Optimize(m Matcher) (opt Matcher) {
 			rightNil = v.right == nil || v.right == NothingMatcher{}
 		)
 		if leftNil && rightNil {
-			return NewText(txt.s)
+			return txt
 		}
 		_, leftSuper := v.left.(SuperMatcher)
 		lp, leftPrefix := v.left.(PrefixMatcher)
@@ -193,19 +193,19 @@ func Optimize(m Matcher) (opt Matcher) {
 		ra, rightAny := v.right.(AnyMatcher)
 		switch {
 		case leftSuper && rightSuper:
-			return NewContains(txt.s)
+			return newContains(txt.s, txt.fold)
 		case leftSuper && rightNil:
-			return NewSuffix(txt.s)
+			return newSuffix(txt.s, txt.fold)
 		case rightSuper && leftNil:
-			return NewPrefix(txt.s)
-		case leftNil && rightSuffix:
-			return NewPrefixSuffix(txt.s, rs.s)
-		case rightNil && leftPrefix:
-			return NewPrefixSuffix(lp.s, txt.s)
+			return newPrefix(txt.s, txt.fold)
+		case leftNil && rightSuffix && rs.fold == txt.fold:
+			return newPrefixSuffix(txt.s, rs.s, txt.fold)
+		case rightNil && leftPrefix && lp.fold == txt.fold:
+			return newPrefixSuffix(lp.s, txt.s, txt.fold)
 		case rightNil && leftAny:
-			return NewSuffixAny(txt.s, la.sep)
+			return newSuffixAny(txt.s, la.sep, txt.fold)
 		case leftNil && rightAny:
-			return NewPrefixAny(txt.s, ra.sep)
+			return newPrefixAny(txt.s, ra.sep, txt.fold)
 		}
 	case Container:
 		var (
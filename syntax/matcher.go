@@ -3,24 +3,37 @@ package syntax
 import (
 	"fmt"
 	"slices"
-	"strings"
+	"strconv"
 	"sync"
+	"unicode"
 	"unicode/utf8"
 )
 
 type AnyMatcher struct {
-	sep []rune
+	sep    []rune
+	period bool
 }
 
 func NewAny(s []rune) AnyMatcher {
-	return AnyMatcher{s}
+	return AnyMatcher{sep: s}
+}
+
+// NewAnyPeriod creates an any matcher that does not match a leading period.
+func NewAnyPeriod(s []rune) AnyMatcher {
+	return AnyMatcher{sep: s, period: true}
 }
 
 func (m AnyMatcher) Match(s string) bool {
+	if m.period && len(s) != 0 && s[0] == '.' {
+		return false
+	}
 	return runesIndexAnyRune(s, m.sep) == -1
 }
 
 func (m AnyMatcher) Index(s string) (int, []int) {
+	if m.period && len(s) != 0 && s[0] == '.' {
+		return 0, segments0
+	}
 	switch i := runesIndexAnyRune(s, m.sep); i {
 	case -1:
 	case 0:
@@ -40,7 +53,7 @@ func (AnyMatcher) Len() int {
 }
 
 func (m AnyMatcher) String() string {
-	return fmt.Sprintf("<any:![%s]>", string(m.sep))
+	return fmt.Sprintf("<any:![%s]%s>", string(m.sep), periodString(m.period))
 }
 
 type AnyOfMatcher struct {
@@ -172,31 +185,282 @@ func (m IndexedSizedAnyOfMatcher) Size() int {
 	return m.runes
 }
 
+// ClassMatcher matches a single character of a named character class.
+type ClassMatcher struct {
+	Name   string
+	Not    bool
+	tables []*unicode.RangeTable
+	fold   bool
+}
+
+// NewClass creates a matcher for the named character class. See
+// [ClassTables] for the class names.
+func NewClass(name string, not bool) ClassMatcher {
+	return ClassMatcher{Name: name, Not: not, tables: ClassTables(name)}
+}
+
+// NewClassFold creates a matcher for the named character class that matches
+// case-insensitively.
+func NewClassFold(name string, not bool) ClassMatcher {
+	m := NewClass(name, not)
+	m.fold = true
+	return m
+}
+
+func (ClassMatcher) Len() int {
+	return 1
+}
+
+func (ClassMatcher) Size() int {
+	return 1
+}
+
+func (m ClassMatcher) Match(s string) (ok bool) {
+	if debugEnabled {
+		done := debugMatching("class", s)
+		defer func() { done(ok) }()
+	}
+	r, w := utf8.DecodeRuneInString(s)
+	if w == 0 || len(s) > w {
+		return false
+	}
+	return m.contains(r) == !m.Not
+}
+
+func (m ClassMatcher) Index(s string) (index int, segments []int) {
+	if debugEnabled {
+		done := debugIndexing("class", s)
+		defer func() { done(index, segments) }()
+	}
+	for i, r := range s {
+		if m.Not != m.contains(r) {
+			return i, segmentsByRuneLength[runeWidth(s, i)]
+		}
+	}
+	return -1, nil
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (m ClassMatcher) String() string {
+	var not string
+	if m.Not {
+		not = "!"
+	}
+	return fmt.Sprintf("<class:%s[%s]%s>", not, m.Name, foldString(m.fold))
+}
+
+// contains reports whether r is in the class.
+func (m ClassMatcher) contains(r rune) bool {
+	if unicode.IsOneOf(m.tables, r) {
+		return true
+	}
+	if m.fold {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if unicode.IsOneOf(m.tables, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CharSetMatcher matches a single character of a set of characters, ranges
+// and named character classes. ASCII characters are matched using a bitmap,
+// and other characters using a sorted table of ranges and the tables of the
+// classes.
+type CharSetMatcher struct {
+	Not       bool
+	ascii     [2]uint64
+	ranges    []unicode.Range32
+	tables    []*unicode.RangeTable
+	notTables [][]*unicode.RangeTable
+	fold      bool
+	items     []unicode.Range32
+	classes   []ClassData
+}
+
+// NewCharSet creates a matcher for the set of character ranges and named
+// character classes. See [ClassTables] for the class names.
+func NewCharSet(ranges []unicode.Range32, classes []ClassData, not bool) CharSetMatcher {
+	m := CharSetMatcher{
+		Not:     not,
+		items:   ranges,
+		classes: classes,
+	}
+	for _, c := range classes {
+		if c.Not {
+			m.notTables = append(m.notTables, ClassTables(c.Name))
+		} else {
+			m.tables = append(m.tables, ClassTables(c.Name)...)
+		}
+	}
+	var v []unicode.Range32
+	for _, r := range ranges {
+		for c := r.Lo; c <= r.Hi && c < utf8.RuneSelf; c++ {
+			m.ascii[c/64] |= 1 << (c % 64)
+		}
+		if r.Hi >= utf8.RuneSelf {
+			v = append(v, unicode.Range32{Lo: max(r.Lo, utf8.RuneSelf), Hi: r.Hi, Stride: 1})
+		}
+	}
+	for c := range rune(utf8.RuneSelf) {
+		if m.inClass(c) {
+			m.ascii[c/64] |= 1 << (c % 64)
+		}
+	}
+	slices.SortFunc(v, func(a, b unicode.Range32) int {
+		return int(a.Lo) - int(b.Lo)
+	})
+	for _, r := range v {
+		if n := len(m.ranges); n != 0 && r.Lo <= m.ranges[n-1].Hi+1 {
+			m.ranges[n-1].Hi = max(m.ranges[n-1].Hi, r.Hi)
+			continue
+		}
+		m.ranges = append(m.ranges, r)
+	}
+	return m
+}
+
+// NewCharSetFold creates a matcher for the set of character ranges and named
+// character classes that matches case-insensitively.
+func NewCharSetFold(ranges []unicode.Range32, classes []ClassData, not bool) CharSetMatcher {
+	m := NewCharSet(ranges, classes, not)
+	m.fold = true
+	return m
+}
+
+func (CharSetMatcher) Len() int {
+	return 1
+}
+
+func (CharSetMatcher) Size() int {
+	return 1
+}
+
+func (m CharSetMatcher) Match(s string) (ok bool) {
+	if debugEnabled {
+		done := debugMatching("char_set", s)
+		defer func() { done(ok) }()
+	}
+	r, w := utf8.DecodeRuneInString(s)
+	if w == 0 || len(s) > w {
+		return false
+	}
+	return m.contains(r) == !m.Not
+}
+
+func (m CharSetMatcher) Index(s string) (index int, segments []int) {
+	if debugEnabled {
+		done := debugIndexing("char_set", s)
+		defer func() { done(index, segments) }()
+	}
+	for i, r := range s {
+		if m.Not != m.contains(r) {
+			return i, segmentsByRuneLength[runeWidth(s, i)]
+		}
+	}
+	return -1, nil
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (m CharSetMatcher) String() string {
+	var not string
+	if m.Not {
+		not = "!"
+	}
+	var s []rune
+	for _, r := range m.items {
+		s = append(s, rune(r.Lo))
+		if r.Hi != r.Lo {
+			s = append(s, '-', rune(r.Hi))
+		}
+	}
+	for _, c := range m.classes {
+		s = append(s, []rune(classString(c))...)
+	}
+	return fmt.Sprintf("<char_set:%s[%s]%s>", not, string(s), foldString(m.fold))
+}
+
+// contains reports whether r is in the set.
+func (m CharSetMatcher) contains(r rune) bool {
+	if m.in(r) {
+		return true
+	}
+	if m.fold {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if m.in(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// in reports whether r is in the set, without folding case.
+func (m CharSetMatcher) in(r rune) bool {
+	if r < utf8.RuneSelf {
+		return m.ascii[r/64]&(1<<(r%64)) != 0
+	}
+	c := uint32(r)
+	i, _ := slices.BinarySearchFunc(m.ranges, c, func(v unicode.Range32, c uint32) int {
+		switch {
+		case v.Hi < c:
+			return -1
+		case c < v.Lo:
+			return 1
+		}
+		return 0
+	})
+	if i < len(m.ranges) && m.ranges[i].Lo <= c && c <= m.ranges[i].Hi {
+		return true
+	}
+	return m.inClass(r)
+}
+
+// inClass reports whether r is in any of the classes of the set.
+func (m CharSetMatcher) inClass(r rune) bool {
+	if unicode.IsOneOf(m.tables, r) {
+		return true
+	}
+	for _, tables := range m.notTables {
+		if !unicode.IsOneOf(tables, r) {
+			return true
+		}
+	}
+	return false
+}
+
 type ContainsMatcher struct {
-	s   string
-	not bool
+	s    string
+	not  bool
+	fold bool
 }
 
 func NewContains(needle string) ContainsMatcher {
-	return ContainsMatcher{needle, false}
+	return ContainsMatcher{needle, false, false}
 }
 
 func NewNotContains(needle string) ContainsMatcher {
-	return ContainsMatcher{needle, true}
+	return ContainsMatcher{needle, true, false}
+}
+
+func newContains(needle string, fold bool) ContainsMatcher {
+	return ContainsMatcher{needle, false, fold}
 }
 
 func (m ContainsMatcher) Match(s string) bool {
-	return strings.Contains(s, m.s) != m.not
+	idx, _ := textIndex(s, m.s, m.fold)
+	return (idx != -1) != m.not
 }
 
 func (m ContainsMatcher) Index(s string) (int, []int) {
 	var offset int
-	idx := strings.Index(s, m.s)
+	idx, n := textIndex(s, m.s, m.fold)
 	if !m.not {
 		if idx == -1 {
 			return -1, nil
 		}
-		offset = idx + len(m.s)
+		offset = idx + n
 		if len(s) <= offset {
 			return 0, []int{offset}
 		}
@@ -221,7 +485,7 @@ func (m ContainsMatcher) String() string {
 	if m.not {
 		not = "!"
 	}
-	return fmt.Sprintf("<contains:%s[%s]>", not, m.s)
+	return fmt.Sprintf("<contains:%s[%s]%s>", not, m.s, foldString(m.fold))
 }
 
 type EveryOfMatcher struct {
@@ -277,49 +541,56 @@ type IndexedEveryOf struct {
 }
 
 func (m IndexedEveryOf) Index(s string) (int, []int) {
-	var index int
-	var offset int
-	// make `in` with cap as len(s),
+	// make the segments with cap as len(s)+1,
 	// cause it is the maximum size of output segments values
-	next := acquireSegments(len(s))
-	current := acquireSegments(len(s))
-	sub := s
-	for i, m := range m.ms {
-		idx, seg := m.Index(sub)
-		if idx == -1 {
-			releaseSegments(next)
-			releaseSegments(current)
-			return -1, nil
-		}
-		if i == 0 {
-			// we use copy here instead of `current = seg`
-			// cause seg is a slice from reusable buffer `in`
-			// and it could be overwritten in next iteration
-			current = append(current, seg...)
-		} else {
-			// clear the next
+	next := acquireSegments(len(s) + 1)
+	current := acquireSegments(len(s) + 1)
+	for index := 0; index <= len(s); {
+		// the matchers only report the segments of their leftmost match, so
+		// each must match at index, else index is moved past the leftmost
+		// match and the matchers are tried again
+		ok := true
+		for i, m := range m.ms {
+			idx, seg := m.Index(s[index:])
+			if idx == -1 {
+				releaseSegments(next)
+				releaseSegments(current)
+				return -1, nil
+			}
+			if idx != 0 {
+				index += idx
+				ok = false
+				break
+			}
+			if i == 0 {
+				// we use copy here instead of `current = seg`
+				// cause seg could be a reusable buffer
+				current = append(current[:0], seg...)
+				continue
+			}
 			next = next[:0]
-			delta := index - (idx + offset)
 			for _, ex := range current {
 				for _, n := range seg {
-					if ex+delta == n {
+					if ex == n {
 						next = append(next, n)
 					}
 				}
 			}
-			if len(next) == 0 {
-				releaseSegments(next)
-				releaseSegments(current)
-				return -1, nil
-			}
-			current = append(current[:0], next...)
+			current, next = next, current
+		}
+		switch {
+		case !ok:
+		case len(current) != 0:
+			releaseSegments(next)
+			return index, current
+		default:
+			_, w := utf8.DecodeRuneInString(s[index:])
+			index += max(w, 1)
 		}
-		index = idx + offset
-		sub = s[index:]
-		offset += idx
 	}
 	releaseSegments(next)
-	return index, current
+	releaseSegments(current)
+	return -1, nil
 }
 
 // String satisfies the [fmt.Stringer] interface.
@@ -336,9 +607,14 @@ func NewList(rs []rune, not bool) ListMatcher {
 	return ListMatcher{rs, not}
 }
 
+// NewListFold creates a list matcher that matches case-insensitively.
+func NewListFold(rs []rune, not bool) ListMatcher {
+	return ListMatcher{runesFold(rs), not}
+}
+
 func (m ListMatcher) Match(s string) bool {
 	r, w := utf8.DecodeRuneInString(s)
-	if len(s) > w {
+	if w == 0 || len(s) > w {
 		// Invalid rune.
 		return false
 	}
@@ -357,7 +633,7 @@ func (ListMatcher) Size() int {
 func (m ListMatcher) Index(s string) (int, []int) {
 	for i, r := range s {
 		if m.not == (runesIndexRune(m.rs, r) == -1) {
-			return i, segmentsByRuneLength[utf8.RuneLen(r)]
+			return i, segmentsByRuneLength[runeWidth(s, i)]
 		}
 	}
 	return -1, nil
@@ -395,12 +671,12 @@ func (m MaxMatcher) Index(s string) (int, []int) {
 	segments := acquireSegments(m.n + 1)
 	segments = append(segments, 0)
 	var count int
-	for i, r := range s {
+	for i := range s {
 		count++
 		if count > m.n {
 			break
 		}
-		segments = append(segments, i+utf8.RuneLen(r))
+		segments = append(segments, i+runeWidth(s, i))
 	}
 	return 0, segments
 }
@@ -440,10 +716,10 @@ func (m MinMatcher) Index(s string) (int, []int) {
 		return -1, nil
 	}
 	segments := acquireSegments(c)
-	for i, r := range s {
+	for i := range s {
 		count++
 		if count >= m.n {
-			segments = append(segments, i+utf8.RuneLen(r))
+			segments = append(segments, i+runeWidth(s, i))
 		}
 	}
 	if len(segments) == 0 {
@@ -489,21 +765,25 @@ func (NothingMatcher) String() string {
 }
 
 type PrefixAnyMatcher struct {
-	s   string
-	sep []rune
-	n   int
+	s    string
+	sep  []rune
+	n    int
+	fold bool
 }
 
 func NewPrefixAny(s string, sep []rune) PrefixAnyMatcher {
-	return PrefixAnyMatcher{s, sep, utf8.RuneCountInString(s)}
+	return newPrefixAny(s, sep, false)
+}
+
+func newPrefixAny(s string, sep []rune, fold bool) PrefixAnyMatcher {
+	return PrefixAnyMatcher{s, sep, utf8.RuneCountInString(s), fold}
 }
 
 func (m PrefixAnyMatcher) Index(s string) (int, []int) {
-	idx := strings.Index(s, m.s)
+	idx, n := textIndex(s, m.s, m.fold)
 	if idx == -1 {
 		return -1, nil
 	}
-	n := len(m.s)
 	sub := s[idx+n:]
 	i := runesIndexAnyRune(sub, m.sep)
 	if i > -1 {
@@ -511,8 +791,8 @@ func (m PrefixAnyMatcher) Index(s string) (int, []int) {
 	}
 	seg := acquireSegments(len(sub) + 1)
 	seg = append(seg, n)
-	for i, r := range sub {
-		seg = append(seg, n+i+utf8.RuneLen(r))
+	for i := range sub {
+		seg = append(seg, n+i+runeWidth(sub, i))
 	}
 	return idx, seg
 }
@@ -522,35 +802,41 @@ func (m PrefixAnyMatcher) Len() int {
 }
 
 func (m PrefixAnyMatcher) Match(s string) bool {
-	if !strings.HasPrefix(s, m.s) {
+	n := textPrefix(s, m.s, m.fold)
+	if n == -1 {
 		return false
 	}
-	return runesIndexAnyRune(s[len(m.s):], m.sep) == -1
+	return runesIndexAnyRune(s[n:], m.sep) == -1
 }
 
 // String satisfies the [fmt.Stringer] interface.
 func (m PrefixAnyMatcher) String() string {
-	return fmt.Sprintf("<prefix_any:%s![%s]>", m.s, string(m.sep))
+	return fmt.Sprintf("<prefix_any:%s![%s]%s>", m.s, string(m.sep), foldString(m.fold))
 }
 
 type PrefixMatcher struct {
-	s string
-	n int
+	s    string
+	n    int
+	fold bool
 }
 
 func NewPrefix(p string) PrefixMatcher {
+	return newPrefix(p, false)
+}
+
+func newPrefix(p string, fold bool) PrefixMatcher {
 	return PrefixMatcher{
-		s: p,
-		n: utf8.RuneCountInString(p),
+		s:    p,
+		n:    utf8.RuneCountInString(p),
+		fold: fold,
 	}
 }
 
 func (m PrefixMatcher) Index(s string) (int, []int) {
-	idx := strings.Index(s, m.s)
+	idx, length := textIndex(s, m.s, m.fold)
 	if idx == -1 {
 		return -1, nil
 	}
-	length := len(m.s)
 	var sub string
 	if len(s) > idx+length {
 		sub = s[idx+length:]
@@ -559,8 +845,8 @@ func (m PrefixMatcher) Index(s string) (int, []int) {
 	}
 	segments := acquireSegments(len(sub) + 1)
 	segments = append(segments, length)
-	for i, r := range sub {
-		segments = append(segments, length+i+utf8.RuneLen(r))
+	for i := range sub {
+		segments = append(segments, length+i+runeWidth(sub, i))
 	}
 	return idx, segments
 }
@@ -570,27 +856,32 @@ func (m PrefixMatcher) Len() int {
 }
 
 func (m PrefixMatcher) Match(s string) bool {
-	return strings.HasPrefix(s, m.s)
+	return textPrefix(s, m.s, m.fold) != -1
 }
 
 // String satisfies the [fmt.Stringer] interface.
 func (m PrefixMatcher) String() string {
-	return fmt.Sprintf("<prefix:%s>", m.s)
+	return fmt.Sprintf("<prefix:%s%s>", m.s, foldString(m.fold))
 }
 
 type PrefixSuffixMatcher struct {
 	p, s string
 	n    int
+	fold bool
 }
 
 func NewPrefixSuffix(prefix, suffix string) PrefixSuffixMatcher {
+	return newPrefixSuffix(prefix, suffix, false)
+}
+
+func newPrefixSuffix(prefix, suffix string, fold bool) PrefixSuffixMatcher {
 	pn := utf8.RuneCountInString(prefix)
 	sn := utf8.RuneCountInString(suffix)
-	return PrefixSuffixMatcher{prefix, suffix, pn + sn}
+	return PrefixSuffixMatcher{prefix, suffix, pn + sn, fold}
 }
 
 func (m PrefixSuffixMatcher) Index(s string) (int, []int) {
-	prefixIdx := strings.Index(s, m.p)
+	prefixIdx, _ := textIndex(s, m.p, m.fold)
 	if prefixIdx == -1 {
 		return -1, nil
 	}
@@ -603,11 +894,11 @@ func (m PrefixSuffixMatcher) Index(s string) (int, []int) {
 	}
 	segments := acquireSegments(len(s) - prefixIdx)
 	for sub := s[prefixIdx:]; ; {
-		suffixIdx := strings.LastIndex(sub, m.s)
+		suffixIdx, n := textLastIndex(sub, m.s, m.fold)
 		if suffixIdx == -1 {
 			break
 		}
-		segments = append(segments, suffixIdx+suffixLen)
+		segments = append(segments, suffixIdx+n)
 		sub = sub[:suffixIdx]
 	}
 	if len(segments) == 0 {
@@ -619,7 +910,8 @@ func (m PrefixSuffixMatcher) Index(s string) (int, []int) {
 }
 
 func (m PrefixSuffixMatcher) Match(s string) bool {
-	return strings.HasPrefix(s, m.p) && strings.HasSuffix(s, m.s)
+	p, q := textPrefix(s, m.p, m.fold), textSuffix(s, m.s, m.fold)
+	return p != -1 && q != -1 && p+q <= len(s)
 }
 
 func (m PrefixSuffixMatcher) Len() int {
@@ -628,16 +920,22 @@ func (m PrefixSuffixMatcher) Len() int {
 
 // String satisfies the [fmt.Stringer] interface.
 func (m PrefixSuffixMatcher) String() string {
-	return fmt.Sprintf("<prefix_suffix:[%s,%s]>", m.p, m.s)
+	return fmt.Sprintf("<prefix_suffix:[%s,%s]%s>", m.p, m.s, foldString(m.fold))
 }
 
 type RangeMatcher struct {
 	Lo, Hi rune
 	Not    bool
+	fold   bool
 }
 
 func NewRange(lo, hi rune, not bool) RangeMatcher {
-	return RangeMatcher{lo, hi, not}
+	return RangeMatcher{Lo: lo, Hi: hi, Not: not}
+}
+
+// NewRangeFold creates a range matcher that matches case-insensitively.
+func NewRangeFold(lo, hi rune, not bool) RangeMatcher {
+	return RangeMatcher{Lo: lo, Hi: hi, Not: not, fold: true}
 }
 
 func (RangeMatcher) Len() int {
@@ -654,11 +952,10 @@ func (m RangeMatcher) Match(s string) (ok bool) {
 		defer func() { done(ok) }()
 	}
 	r, w := utf8.DecodeRuneInString(s)
-	if len(s) > w {
+	if w == 0 || len(s) > w {
 		return false
 	}
-	inRange := r >= m.Lo && r <= m.Hi
-	return inRange == !m.Not
+	return m.contains(r) == !m.Not
 }
 
 func (m RangeMatcher) Index(s string) (index int, segments []int) {
@@ -667,8 +964,8 @@ func (m RangeMatcher) Index(s string) (index int, segments []int) {
 		defer func() { done(index, segments) }()
 	}
 	for i, r := range s {
-		if m.Not != (r >= m.Lo && r <= m.Hi) {
-			return i, segmentsByRuneLength[utf8.RuneLen(r)]
+		if m.Not != m.contains(r) {
+			return i, segmentsByRuneLength[runeWidth(s, i)]
 		}
 	}
 	return -1, nil
@@ -680,13 +977,27 @@ func (m RangeMatcher) String() string {
 	if m.Not {
 		not = "!"
 	}
-	return fmt.Sprintf("<range:%s[%s,%s]>", not, string(m.Lo), string(m.Hi))
+	return fmt.Sprintf("<range:%s[%s,%s]%s>", not, string(m.Lo), string(m.Hi), foldString(m.fold))
+}
+
+// contains reports whether r is in the range.
+func (m RangeMatcher) contains(r rune) bool {
+	if m.Lo <= r && r <= m.Hi {
+		return true
+	}
+	if m.fold {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if m.Lo <= f && f <= m.Hi {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type RowMatcher struct {
-	ms  []MatchIndexSizer
-	n   int
-	seg []int
+	ms []MatchIndexSizer
+	n  int
 }
 
 func NewRow(ms []MatchIndexSizer) RowMatcher {
@@ -695,9 +1006,8 @@ func NewRow(ms []MatchIndexSizer) RowMatcher {
 		r += m.Size()
 	}
 	return RowMatcher{
-		ms:  ms,
-		n:   r,
-		seg: []int{r},
+		ms: ms,
+		n:  r,
 	}
 }
 
@@ -731,10 +1041,12 @@ func (m RowMatcher) Index(s string) (index int, segments []int) {
 		if i == -1 {
 			return -1, nil
 		}
-		if m.matchAll(s[i:]) {
-			return j + i, m.seg
+		j += i
+		if m.matchAll(s[j:]) {
+			// the segment is the byte length of the n runes matched
+			return j, []int{len(runesHead(s[j:], m.n))}
 		}
-		_, x := utf8.DecodeRuneInString(s[i:])
+		_, x := utf8.DecodeRuneInString(s[j:])
 		j += x
 	}
 	return -1, nil
@@ -765,17 +1077,102 @@ func (m RowMatcher) matchAll(s string) bool {
 }
 
 // single represents ?
+// SequenceMatcher matches a value of a brace sequence, testing the value
+// directly rather than expanding the sequence.
+type SequenceMatcher struct {
+	d    SequenceData
+	n    int
+	fold bool
+}
+
+// NewSequence creates a matcher for the brace sequence.
+func NewSequence(d SequenceData) SequenceMatcher {
+	return SequenceMatcher{d: d, n: d.minLen()}
+}
+
+// NewSequenceFold creates a matcher for the brace sequence that matches
+// characters case-insensitively.
+func NewSequenceFold(d SequenceData) SequenceMatcher {
+	m := NewSequence(d)
+	m.fold = d.Chars
+	return m
+}
+
+func (m SequenceMatcher) Len() int {
+	return m.n
+}
+
+func (m SequenceMatcher) Match(s string) (ok bool) {
+	if debugEnabled {
+		done := debugMatching("sequence", s)
+		defer func() { done(ok) }()
+	}
+	if m.d.Contains(s) {
+		return true
+	}
+	if m.fold {
+		r, w := utf8.DecodeRuneInString(s)
+		if w == 0 || w != len(s) {
+			return false
+		}
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if m.d.Contains(string(f)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m SequenceMatcher) Index(s string) (index int, segments []int) {
+	if debugEnabled {
+		done := debugIndexing("sequence", s)
+		defer func() { done(index, segments) }()
+	}
+	n := m.d.maxLen()
+	for i := range s {
+		for j := i + 1; j <= min(i+n, len(s)); j++ {
+			if m.Match(s[i:j]) {
+				if segments == nil {
+					segments = acquireSegments(n)
+				}
+				segments = append(segments, j-i)
+			}
+		}
+		if segments != nil {
+			return i, segments
+		}
+	}
+	return -1, nil
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (m SequenceMatcher) String() string {
+	s := m.d.format(m.d.Start) + ".." + m.d.format(m.d.End)
+	if m.d.Step != 1 {
+		s += ".." + strconv.Itoa(m.d.Step)
+	}
+	return fmt.Sprintf("<sequence:%s%s>", s, foldString(m.fold))
+}
+
 type SingleMatcher struct {
-	sep []rune
+	sep    []rune
+	period bool
 }
 
 func NewSingle(s []rune) SingleMatcher {
-	return SingleMatcher{s}
+	return SingleMatcher{sep: s}
+}
+
+// NewSinglePeriod creates a single matcher that does not match a leading
+// period, or a period following a separator.
+func NewSinglePeriod(s []rune) SingleMatcher {
+	return SingleMatcher{sep: s, period: true}
 }
 
 func (m SingleMatcher) Match(v string) bool {
 	r, w := utf8.DecodeRuneInString(v)
-	if len(v) > w {
+	if w == 0 || len(v) > w || m.period && r == '.' {
 		return false
 	}
 	return runesIndexRune(m.sep, r) == -1
@@ -791,8 +1188,8 @@ func (SingleMatcher) Size() int {
 
 func (m SingleMatcher) Index(v string) (int, []int) {
 	for i, r := range v {
-		if runesIndexRune(m.sep, r) == -1 {
-			return i, segmentsByRuneLength[utf8.RuneLen(r)]
+		if runesIndexRune(m.sep, r) == -1 && !(m.period && leadingPeriod(v, i, m.sep)) {
+			return i, segmentsByRuneLength[runeWidth(v, i)]
 		}
 	}
 	return -1, nil
@@ -801,28 +1198,33 @@ func (m SingleMatcher) Index(v string) (int, []int) {
 // String satisfies the [fmt.Stringer] interface.
 func (m SingleMatcher) String() string {
 	if len(m.sep) == 0 {
-		return "<single>"
+		return fmt.Sprintf("<single%s>", periodString(m.period))
 	}
-	return fmt.Sprintf("<single:![%s]>", string(m.sep))
+	return fmt.Sprintf("<single:![%s]%s>", string(m.sep), periodString(m.period))
 }
 
 type SuffixAnyMatcher struct {
-	s   string
-	sep []rune
-	n   int
+	s    string
+	sep  []rune
+	n    int
+	fold bool
 }
 
 func NewSuffixAny(s string, sep []rune) SuffixAnyMatcher {
-	return SuffixAnyMatcher{s, sep, utf8.RuneCountInString(s)}
+	return newSuffixAny(s, sep, false)
+}
+
+func newSuffixAny(s string, sep []rune, fold bool) SuffixAnyMatcher {
+	return SuffixAnyMatcher{s, sep, utf8.RuneCountInString(s), fold}
 }
 
 func (m SuffixAnyMatcher) Index(v string) (int, []int) {
-	idx := strings.Index(v, m.s)
+	idx, n := textIndex(v, m.s, m.fold)
 	if idx == -1 {
 		return -1, nil
 	}
 	i := runesLastIndexAnyRune(v[:idx], m.sep) + 1
-	return i, []int{idx + len(m.s) - i}
+	return i, []int{idx + n - i}
 }
 
 func (m SuffixAnyMatcher) Len() int {
@@ -830,24 +1232,30 @@ func (m SuffixAnyMatcher) Len() int {
 }
 
 func (m SuffixAnyMatcher) Match(v string) bool {
-	if !strings.HasSuffix(v, m.s) {
+	n := textSuffix(v, m.s, m.fold)
+	if n == -1 {
 		return false
 	}
-	return runesIndexAnyRune(v[:len(v)-len(m.s)], m.sep) == -1
+	return runesIndexAnyRune(v[:len(v)-n], m.sep) == -1
 }
 
 // String satisfies the [fmt.Stringer] interface.
 func (m SuffixAnyMatcher) String() string {
-	return fmt.Sprintf("<suffix_any:![%s]%s>", string(m.sep), m.s)
+	return fmt.Sprintf("<suffix_any:![%s]%s%s>", string(m.sep), m.s, foldString(m.fold))
 }
 
 type SuffixMatcher struct {
-	s string
-	n int
+	s    string
+	n    int
+	fold bool
 }
 
 func NewSuffix(s string) SuffixMatcher {
-	return SuffixMatcher{s, utf8.RuneCountInString(s)}
+	return newSuffix(s, false)
+}
+
+func newSuffix(s string, fold bool) SuffixMatcher {
+	return SuffixMatcher{s, utf8.RuneCountInString(s), fold}
 }
 
 func (m SuffixMatcher) Len() int {
@@ -855,29 +1263,48 @@ func (m SuffixMatcher) Len() int {
 }
 
 func (m SuffixMatcher) Match(v string) bool {
-	return strings.HasSuffix(v, m.s)
+	return textSuffix(v, m.s, m.fold) != -1
 }
 
 func (m SuffixMatcher) Index(v string) (int, []int) {
-	if i := strings.Index(v, m.s); i != -1 {
-		return 0, []int{i + len(m.s)}
+	var segments []int
+	for offset := 0; offset <= len(v); {
+		i, n := textIndex(v[offset:], m.s, m.fold)
+		if i == -1 {
+			break
+		}
+		segments = append(segments, offset+i+n)
+		_, w := utf8.DecodeRuneInString(v[offset+i:])
+		offset += i + max(w, 1)
 	}
-	return -1, nil
+	if segments == nil {
+		return -1, nil
+	}
+	return 0, segments
 }
 
 // String satisfies the [fmt.Stringer] interface.
 func (m SuffixMatcher) String() string {
-	return fmt.Sprintf("<suffix:%s>", m.s)
+	return fmt.Sprintf("<suffix:%s%s>", m.s, foldString(m.fold))
 }
 
-type SuperMatcher struct{}
+type SuperMatcher struct {
+	sep    []rune
+	period bool
+}
 
 func NewSuper() SuperMatcher {
 	return SuperMatcher{}
 }
 
-func (m SuperMatcher) Match(_ string) bool {
-	return true
+// NewSuperPeriod creates a super matcher that does not match a leading
+// period, or a period following any of the separators.
+func NewSuperPeriod(sep []rune) SuperMatcher {
+	return SuperMatcher{sep: sep, period: true}
+}
+
+func (m SuperMatcher) Match(s string) bool {
+	return !m.period || m.periodIndex(s) == -1
 }
 
 func (m SuperMatcher) Len() int {
@@ -885,6 +1312,11 @@ func (m SuperMatcher) Len() int {
 }
 
 func (m SuperMatcher) Index(v string) (int, []int) {
+	if m.period {
+		if i := m.periodIndex(v); i != -1 {
+			v = v[:i]
+		}
+	}
 	seg := acquireSegments(len(v) + 1)
 	for i := range v {
 		seg = append(seg, i)
@@ -893,9 +1325,19 @@ func (m SuperMatcher) Index(v string) (int, []int) {
 	return 0, seg
 }
 
+// periodIndex returns the index of the first leading period in s, or -1.
+func (m SuperMatcher) periodIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '.' && leadingPeriod(s, i, m.sep) {
+			return i
+		}
+	}
+	return -1
+}
+
 // String satisfies the [fmt.Stringer] interface.
 func (m SuperMatcher) String() string {
-	return fmt.Sprintf("<super>")
+	return fmt.Sprintf("<super%s>", periodString(m.period))
 }
 
 // TextMatcher represents raw string to match
@@ -904,25 +1346,42 @@ type TextMatcher struct {
 	runes int
 	bytes int
 	seg   []int
+	fold  bool
 }
 
 func NewText(s string) TextMatcher {
+	return newText(s, false)
+}
+
+// NewTextFold creates a text matcher that matches case-insensitively.
+func NewTextFold(s string) TextMatcher {
+	return newText(s, true)
+}
+
+func newText(s string, fold bool) TextMatcher {
 	return TextMatcher{
 		s:     s,
 		runes: utf8.RuneCountInString(s),
 		bytes: len(s),
 		seg:   []int{len(s)},
+		fold:  fold,
 	}
 }
 
 func (m TextMatcher) Match(s string) bool {
-	return m.s == s
+	if !m.fold {
+		return m.s == s
+	}
+	return textPrefix(s, m.s, true) == len(s)
 }
 
 func (m TextMatcher) Index(s string) (int, []int) {
-	i := strings.Index(s, m.s)
-	if i == -1 {
+	i, n := textIndex(s, m.s, m.fold)
+	switch {
+	case i == -1:
 		return -1, nil
+	case n != len(m.s):
+		return i, []int{n}
 	}
 	return i, m.seg
 }
@@ -941,7 +1400,7 @@ func (m TextMatcher) Size() int {
 
 // String satisfies the [fmt.Stringer] interface.
 func (m TextMatcher) String() string {
-	return fmt.Sprintf("<text:`%v`>", m.s)
+	return fmt.Sprintf("<text:`%v`%s>", m.s, foldString(m.fold))
 }
 
 type TreeMatcher struct {
@@ -1181,3 +1640,46 @@ func releaseSegments(s []int) {
 	}
 	segmentsPools[getTableIndex(c)].Put(s)
 }
+
+// foldString returns the marker used in the string representation of
+// case-insensitive matchers.
+func foldString(fold bool) string {
+	if fold {
+		return "/i"
+	}
+	return ""
+}
+
+// periodString returns the marker used in the string representation of
+// matchers that do not match a leading period.
+func periodString(period bool) string {
+	if period {
+		return "/p"
+	}
+	return ""
+}
+
+// slash is the separator of paths.
+var slash = []rune{'/'}
+
+// periodSep returns the separators a leading period may follow using the
+// flags.
+func periodSep(sep []rune, flags Flags) []rune {
+	if flags&NoDotGlob != 0 && len(sep) == 0 {
+		return slash
+	}
+	return sep
+}
+
+// leadingPeriod reports whether s[i] is a period at the start of s, or
+// following a separator.
+func leadingPeriod(s string, i int, sep []rune) bool {
+	if s[i] != '.' {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return runesIndexRune(sep, r) != -1
+}
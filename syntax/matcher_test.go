@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"unicode"
 )
 
 func TestIndexedAnyOf(t *testing.T) {
@@ -86,6 +87,98 @@ func TestAnyIndex(t *testing.T) {
 	}
 }
 
+func TestCharSet(t *testing.T) {
+	m := NewCharSet([]unicode.Range32{
+		{Lo: 'a', Hi: 'z', Stride: 1},
+		{Lo: '_', Hi: '_', Stride: 1},
+		{Lo: 0x3b1, Hi: 0x3c9, Stride: 1},
+		{Lo: 0x3b0, Hi: 0x3b5, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x4e00, Stride: 1},
+	}, []ClassData{{Name: "digit"}}, false)
+	for i, test := range []struct {
+		s   string
+		exp bool
+	}{
+		{"a", true},
+		{"_", true},
+		{"A", false},
+		{"5", true},
+		{"٣", true},
+		{"ΰ", true},
+		{"ω", true},
+		{"Ω", false},
+		{"一", true},
+		{"丁", false},
+		{"", false},
+		{"ab", false},
+	} {
+		if b := m.Match(test.s); b != test.exp {
+			t.Errorf("#%d %q: expected %t, got: %t", i, test.s, test.exp, b)
+		}
+	}
+	if s, exp := m.String(), "<char_set:[a-z_α-ωΰ-ε一[:digit:]]>"; s != exp {
+		t.Errorf("expected %s, got: %s", exp, s)
+	}
+	if !NewCharSetFold(m.items, m.classes, false).Match("Ω") {
+		t.Errorf("expected fold match")
+	}
+	index, segments := m.Index("ABCω")
+	if exp := 3; index != exp {
+		t.Errorf("expected %d, got: %d", exp, index)
+	}
+	if exp := []int{2}; !reflect.DeepEqual(segments, exp) {
+		t.Errorf("expected %v, got: %v", exp, segments)
+	}
+}
+
+func TestClassIndex(t *testing.T) {
+	for id, test := range []struct {
+		name     string
+		not      bool
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{
+			"digit",
+			false,
+			"abc1",
+			3,
+			[]int{1},
+		},
+		{
+			"digit",
+			false,
+			"abc٣",
+			3,
+			[]int{2},
+		},
+		{
+			"digit",
+			true,
+			"12a",
+			2,
+			[]int{1},
+		},
+		{
+			"upper",
+			false,
+			"abc",
+			-1,
+			nil,
+		},
+	} {
+		m := NewClass(test.name, test.not)
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
+
 func TestContainsIndex(t *testing.T) {
 	for id, test := range []struct {
 		prefix   string
@@ -123,7 +216,7 @@ func TestContainsIndex(t *testing.T) {
 			[]int{0, 1, 2, 3},
 		},
 	} {
-		p := ContainsMatcher{test.prefix, test.not}
+		p := ContainsMatcher{s: test.prefix, not: test.not}
 		index, segments := p.Index(test.fixture)
 		if index != test.index {
 			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
@@ -161,6 +254,26 @@ func TestIndexedEveryOf(t *testing.T) {
 			1,
 			[]int{2},
 		},
+		{
+			Matchers{
+				NewMin(2),
+				NewMax(2),
+				NewAny([]rune{'a'}),
+			},
+			"a-2-",
+			1,
+			[]int{2},
+		},
+		{
+			Matchers{
+				NewAny(nil),
+				NewPrefix("b"),
+				NewSuffix("c"),
+			},
+			"acbc",
+			2,
+			[]int{2},
+		},
 	} {
 		everyOf := NewEveryOf(test.matchers).(IndexedEveryOf)
 		index, segments := everyOf.Index(test.fixture)
@@ -447,6 +560,42 @@ func TestRowIndex(t *testing.T) {
 			-1,
 			nil,
 		},
+		{
+			[]MatchIndexSizer{
+				NewText("a"),
+				NewText("b"),
+			},
+			"xaab",
+			2,
+			[]int{2},
+		},
+		{
+			[]MatchIndexSizer{
+				NewSingle(nil),
+				NewText("b"),
+			},
+			"äb",
+			0,
+			[]int{3},
+		},
+		{
+			[]MatchIndexSizer{
+				NewText("ü"),
+				NewSingle(nil),
+			},
+			"äüx",
+			2,
+			[]int{3},
+		},
+		{
+			[]MatchIndexSizer{
+				NewSingle(nil),
+				NewSingle(nil),
+			},
+			"\xff\xff",
+			0,
+			[]int{2},
+		},
 	} {
 		p := NewRow(test.matchers)
 		index, segments := p.Index(test.fixture)
@@ -459,6 +608,36 @@ func TestRowIndex(t *testing.T) {
 	}
 }
 
+func TestSequenceIndex(t *testing.T) {
+	for id, test := range []struct {
+		seq      string
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{"1..30", "log.25.gz", 4, []int{1, 2}},
+		{"1..30", "log.31.gz", 4, []int{1}},
+		{"10..30", "log.31.gz", -1, nil},
+		{"01..12", "x/3/07", 4, []int{2}},
+		{"-5..5..5", "a-5", 1, []int{2}},
+		{"a..f", "xyzd", 3, []int{1}},
+		{"a..f", "xyz", -1, nil},
+	} {
+		d, ok := parseSequence(test.seq)
+		if !ok {
+			t.Fatalf("#%d expected sequence %q to parse", id, test.seq)
+		}
+		m := NewSequence(d)
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
+
 func TestSingleIndex(t *testing.T) {
 	for id, test := range []struct {
 		separators []rune
@@ -478,6 +657,12 @@ func TestSingleIndex(t *testing.T) {
 			-1,
 			nil,
 		},
+		{
+			nil,
+			"\xffa",
+			0,
+			[]int{1},
+		},
 	} {
 		p := NewSingle(test.separators)
 		index, segments := p.Index(test.fixture)
@@ -490,6 +675,28 @@ func TestSingleIndex(t *testing.T) {
 	}
 }
 
+func TestSingleRuneMatch(t *testing.T) {
+	for id, test := range []struct {
+		m   Matcher
+		s   string
+		exp bool
+	}{
+		{NewSingle(nil), "", false},
+		{NewSingle(nil), "a", true},
+		{NewSingle(nil), "ab", false},
+		{NewList([]rune("ab"), false), "", false},
+		{NewList([]rune("ab"), true), "", false},
+		{NewList([]rune("ab"), true), "c", true},
+		{NewRange('a', 'c', false), "", false},
+		{NewRange('a', 'c', true), "", false},
+		{NewRange('a', 'c', true), "d", true},
+	} {
+		if b := test.m.Match(test.s); b != test.exp {
+			t.Errorf("#%d unexpected match of %q: exp: %t, act: %t", id, test.s, test.exp, b)
+		}
+	}
+}
+
 func TestSuffixAnyIndex(t *testing.T) {
 	for id, test := range []struct {
 		suffix     string
@@ -550,6 +757,18 @@ func TestSuffixIndex(t *testing.T) {
 			0,
 			[]int{5},
 		},
+		{
+			"/",
+			"a/b/c",
+			0,
+			[]int{2, 4},
+		},
+		{
+			"aa",
+			"aaa",
+			0,
+			[]int{2, 3},
+		},
 	} {
 		p := NewSuffix(test.prefix)
 		index, segments := p.Index(test.fixture)
@@ -706,3 +925,33 @@ func TestSuperIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestPeriodIndex(t *testing.T) {
+	for id, test := range []struct {
+		matcher  Matcher
+		fixture  string
+		match    bool
+		index    int
+		segments []int
+	}{
+		{NewAnyPeriod([]rune{'/'}), ".ab", false, 0, []int{0}},
+		{NewAnyPeriod([]rune{'/'}), "a.b", true, 0, []int{0, 1, 2, 3}},
+		{NewSinglePeriod([]rune{'/'}), ".", false, -1, nil},
+		{NewSinglePeriod([]rune{'/'}), "./.a", false, 3, []int{1}},
+		{NewSinglePeriod(nil), "..", false, 1, []int{1}},
+		{NewSuperPeriod([]rune{'/'}), "a/b.c", true, 0, []int{0, 1, 2, 3, 4, 5}},
+		{NewSuperPeriod([]rune{'/'}), "a/.b", false, 0, []int{0, 1, 2}},
+		{NewSuperPeriod(nil), "a/.b", true, 0, []int{0, 1, 2, 3, 4}},
+	} {
+		if match := test.matcher.Match(test.fixture); match != test.match {
+			t.Errorf("#%d unexpected match: exp: %t, act: %t", id, test.match, match)
+		}
+		index, segments := test.matcher.(Indexer).Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package syntax
 import (
 	"bytes"
 	"fmt"
+	"unicode"
 )
 
 type Type int
@@ -17,6 +18,14 @@ const (
 	Super
 	Single
 	AnyOf
+	ZeroOrOne
+	ZeroOrMore
+	OneOrMore
+	ExactlyOne
+	Not
+	Class
+	CharSet
+	Sequence
 )
 
 func (typ Type) String() string {
@@ -39,6 +48,22 @@ func (typ Type) String() string {
 		return "Single"
 	case AnyOf:
 		return "AnyOf"
+	case ZeroOrOne:
+		return "ZeroOrOne"
+	case ZeroOrMore:
+		return "ZeroOrMore"
+	case OneOrMore:
+		return "OneOrMore"
+	case ExactlyOne:
+		return "ExactlyOne"
+	case Not:
+		return "Not"
+	case Class:
+		return "Class"
+	case CharSet:
+		return "CharSet"
+	case Sequence:
+		return "Sequence"
 	}
 	return ""
 }
@@ -72,7 +97,58 @@ func (node *Node) Insert(children ...*Node) {
 
 // Match builds the matcher for the node.
 func (node *Node) Match(sep []rune) (Matcher, error) {
-	return buildMatch(node, sep)
+	return node.MatchFlags(sep, 0)
+}
+
+// MatchFlags builds the matcher for the node using the flags. Trees that
+// cannot be built from the other matchers (such as those containing
+// repeated or negated pattern lists) are matched by a [BacktrackMatcher].
+func (node *Node) MatchFlags(sep []rune, flags Flags) (Matcher, error) {
+	if needsBacktrack(node, sep, flags) {
+		return NewBacktrack(node, sep, flags), nil
+	}
+	return buildMatch(node, sep, flags)
+}
+
+// needsBacktrack reports whether the tree can only be matched by
+// backtracking using the separators and flags. Of the built matchers, only
+// those for a single `*`, `**` or `?` track leading periods.
+func needsBacktrack(node *Node, sep []rune, flags Flags) bool {
+	switch {
+	case flags&LeadingDir != 0:
+		return true
+	case flags&(Period|NoDotGlob) != 0 && node.Type == Pattern && len(node.Children) == 1:
+		switch node.Children[0].Type {
+		case Any, Super:
+			return false
+		case Single:
+			return len(periodSep(sep, flags)) != len(sep)
+		}
+	}
+	return hasBacktrack(node, flags)
+}
+
+// hasBacktrack reports whether the tree contains nodes that can only be
+// matched by backtracking using the flags.
+func hasBacktrack(node *Node, flags Flags) bool {
+	switch node.Type {
+	case ZeroOrMore, OneOrMore, Not:
+		return true
+	case List, Range, Class, CharSet:
+		if flags&(Pathname|Period|NoDotGlob) != 0 {
+			return true
+		}
+	case Any, Super, Single:
+		if flags&(Period|NoDotGlob) != 0 {
+			return true
+		}
+	}
+	for _, c := range node.Children {
+		if hasBacktrack(c, flags) {
+			return true
+		}
+	}
+	return false
 }
 
 func (node *Node) Equal(n *Node) bool {
@@ -120,6 +196,25 @@ type RangeData struct {
 	Lo, Hi rune
 }
 
+type ClassData struct {
+	Not  bool
+	Name string
+}
+
+type CharSetData struct {
+	Not bool
+}
+
+// SequenceData is a brace sequence (`{x..y}` or `{x..y..incr}`) of the
+// integers or characters from Start to End (inclusive), in increments of
+// Step. Integers are zero-padded to Width when Width is non-zero.
+type SequenceData struct {
+	Start, End int
+	Step       int
+	Width      int
+	Chars      bool
+}
+
 type TextData struct {
 	Text string
 }
@@ -127,7 +222,7 @@ type TextData struct {
 // TODO use constructor with all matchers, and to their structs private
 // TODO glue multiple Text nodes (like after QuoteMeta)
 
-func buildMatch(node *Node, sep []rune) (m Matcher, err error) {
+func buildMatch(node *Node, sep []rune, flags Flags) (m Matcher, err error) {
 	if debugEnabled {
 		debugEnterPrefix("compiler: compiling %s", node)
 		defer func() {
@@ -142,7 +237,7 @@ func buildMatch(node *Node, sep []rune) (m Matcher, err error) {
 	// todo this could be faster on pattern_alternatives_combine_lite (see glob_test.go)
 	if n := Minimize(node); n != nil {
 		debugLogf("minimized tree -> %s", node, n)
-		r, err := buildMatch(n, sep)
+		r, err := buildMatch(n, sep, flags)
 		if debugEnabled {
 			if err != nil {
 				debugLogf("compiler: compile minimized tree failed: %v", err)
@@ -158,16 +253,21 @@ func buildMatch(node *Node, sep []rune) (m Matcher, err error) {
 	}
 	switch node.Type {
 	case AnyOf:
-		matchers, err := buildNodeMatch(node.Children, sep)
+		matchers, err := buildNodeMatch(node.Children, sep, flags)
 		if err != nil {
 			return nil, err
 		}
 		return NewAnyOf(matchers...), nil
+	case ExactlyOne:
+		return buildMatch(&Node{Type: AnyOf, Children: node.Children}, sep, flags)
+	case ZeroOrOne:
+		children := append([]*Node{New(Pattern, nil)}, node.Children...)
+		return buildMatch(&Node{Type: AnyOf, Children: children}, sep, flags)
 	case Pattern:
 		if len(node.Children) == 0 {
 			return NewNothing(), nil
 		}
-		matchers, err := buildNodeMatch(node.Children, sep)
+		matchers, err := buildNodeMatch(node.Children, sep, flags)
 		if err != nil {
 			return nil, err
 		}
@@ -176,32 +276,94 @@ func buildMatch(node *Node, sep []rune) (m Matcher, err error) {
 			return nil, err
 		}
 	case Any:
-		m = NewAny(sep)
+		switch {
+		case flags&NoDotGlob != 0 && len(sep) == 0:
+			m = NewSuperPeriod(slash)
+		case flags&(Period|NoDotGlob) != 0:
+			m = NewAnyPeriod(sep)
+		default:
+			m = NewAny(sep)
+		}
 	case Super:
-		m = NewSuper()
+		if flags&(Period|NoDotGlob) != 0 {
+			m = NewSuperPeriod(periodSep(sep, flags))
+		} else {
+			m = NewSuper()
+		}
 	case Single:
-		m = NewSingle(sep)
+		if flags&(Period|NoDotGlob) != 0 {
+			m = NewSinglePeriod(sep)
+		} else {
+			m = NewSingle(sep)
+		}
 	case Nothing:
 		m = NewNothing()
 	case List:
 		l := node.Value.(ListData)
-		m = NewList([]rune(l.Chars), l.Not)
+		if flags&FoldCase != 0 {
+			m = NewListFold([]rune(l.Chars), l.Not)
+		} else {
+			m = NewList([]rune(l.Chars), l.Not)
+		}
 	case Range:
 		r := node.Value.(RangeData)
-		m = NewRange(r.Lo, r.Hi, r.Not)
+		if flags&FoldCase != 0 {
+			m = NewRangeFold(r.Lo, r.Hi, r.Not)
+		} else {
+			m = NewRange(r.Lo, r.Hi, r.Not)
+		}
+	case Class:
+		c := node.Value.(ClassData)
+		if flags&FoldCase != 0 {
+			m = NewClassFold(c.Name, c.Not)
+		} else {
+			m = NewClass(c.Name, c.Not)
+		}
+	case CharSet:
+		var ranges []unicode.Range32
+		var classes []ClassData
+		for _, c := range node.Children {
+			switch v := c.Value.(type) {
+			case ListData:
+				for _, r := range v.Chars {
+					ranges = append(ranges, unicode.Range32{Lo: uint32(r), Hi: uint32(r), Stride: 1})
+				}
+			case RangeData:
+				ranges = append(ranges, unicode.Range32{Lo: uint32(v.Lo), Hi: uint32(v.Hi), Stride: 1})
+			case ClassData:
+				classes = append(classes, v)
+			}
+		}
+		not := node.Value.(CharSetData).Not
+		if flags&FoldCase != 0 {
+			m = NewCharSetFold(ranges, classes, not)
+		} else {
+			m = NewCharSet(ranges, classes, not)
+		}
+	case Sequence:
+		d := node.Value.(SequenceData)
+		if flags&FoldCase != 0 {
+			m = NewSequenceFold(d)
+		} else {
+			m = NewSequence(d)
+		}
 	case Text:
 		t := node.Value.(TextData)
-		m = NewText(t.Text)
+		if flags&FoldCase != 0 {
+			m = NewTextFold(t.Text)
+		} else {
+			m = NewText(t.Text)
+		}
 	default:
 		return nil, fmt.Errorf("could not compile tree: unknown node type %s (%d)", node.Type, int(node.Type))
 	}
 	return Optimize(m), nil
 }
 
-func buildNodeMatch(ns []*Node, sep []rune) ([]Matcher, error) {
+func buildNodeMatch(ns []*Node, sep []rune, flags Flags) ([]Matcher, error) {
 	var matchers []Matcher
 	for _, n := range ns {
-		m, err := buildMatch(n, sep)
+		m, err := buildMatch(n, sep, flags)
 		if err != nil {
 			return nil, err
 		}
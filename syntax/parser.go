@@ -1,25 +1,75 @@
 package syntax
 
 import (
-	"errors"
-	"fmt"
+	"cmp"
+	"slices"
+	"strings"
 	"unicode/utf8"
 )
 
-// Parse builds a tree from the tokens read from the lexer.
+// Parse builds a tree from the tokens read from the lexer. Syntax errors are
+// returned as an [*Error].
 func Parse(l *Lexer) (*Node, error) {
-	return parse(l)
+	tree, err := (&parser{lexer: l, strict: l.flags&Strict != 0}).parse()
+	switch {
+	case l.err != nil:
+		return nil, l.err
+	case err != nil:
+		if e, ok := err.(*Error); ok {
+			e.Pattern = l.src
+		}
+		return nil, err
+	}
+	if l.flags&DoubleStar != 0 {
+		doubleStar(tree, true, true)
+	}
+	return tree, nil
+}
+
+// ParseAll builds a best-effort tree from the tokens read from the new lexer,
+// recovering from syntax errors instead of stopping at the first one. Returns
+// the tree and the errors, in order of offset. The errors include problems
+// tolerated by [Parse], such as a missing `}` or a trailing `\`.
+func ParseAll(l *Lexer) (*Node, []*Error) {
+	l.recover = true
+	p := &parser{lexer: l, recover: true}
+	tree, _ := p.parse()
+	for _, e := range p.errs {
+		e.Pattern = l.src
+	}
+	errs := append(l.errs, p.errs...)
+	slices.SortStableFunc(errs, func(a, b *Error) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	if l.flags&DoubleStar != 0 {
+		doubleStar(tree, true, true)
+	}
+	return tree, errs
 }
 
 type lexer interface {
 	Next() Token
 }
 
+// parser holds the state of a parse.
+type parser struct {
+	lexer
+	// recover continues the parse after errors, which are added to errs.
+	recover bool
+	errs    []*Error
+	// strict fails the parse on problems that are otherwise tolerated.
+	strict bool
+}
+
 func parse(l lexer) (*Node, error) {
+	return (&parser{lexer: l}).parse()
+}
+
+func (p *parser) parse() (*Node, error) {
 	tree := New(Pattern, nil)
 	var err error
 	for f, node := parseNode, tree; f != nil; {
-		f, node, err = f(node, l)
+		f, node, err = f(node, p)
 		if err != nil {
 			return nil, err
 		}
@@ -27,15 +77,46 @@ func parse(l lexer) (*Node, error) {
 	return tree, nil
 }
 
-type parseFunc func(*Node, lexer) (parseFunc, *Node, error)
+// fail returns the error, or when recovering, adds it to the errors and
+// returns nil.
+func (p *parser) fail(err *Error) error {
+	if !p.recover {
+		return err
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// problem returns the error for a problem that is otherwise tolerated when
+// strict, or when recovering, adds it to the errors and returns nil.
+func (p *parser) problem(err *Error) error {
+	switch {
+	case p.recover:
+		p.errs = append(p.errs, err)
+	case p.strict:
+		return err
+	}
+	return nil
+}
+
+// emptyAlt returns the problem for the pattern node when it is an empty
+// alternative of a `{...}` ended by the token.
+func (p *parser) emptyAlt(node *Node, token Token) error {
+	if len(node.Children) != 0 || node.Parent == nil || node.Parent.Type != AnyOf {
+		return nil
+	}
+	return p.problem(errorf(token, ErrEmptyAlt, "empty alternative"))
+}
+
+type parseFunc func(*Node, *parser) (parseFunc, *Node, error)
 
-func parseNode(node *Node, l lexer) (parseFunc, *Node, error) {
+func parseNode(node *Node, l *parser) (parseFunc, *Node, error) {
 	for {
 		switch token := l.Next(); token.Token {
 		case TokenEOF:
 			return nil, node, nil
 		case TokenError:
-			return nil, node, errors.New(token.Raw)
+			return nil, node, l.fail(&Error{Offset: token.Offset, Msg: token.Raw})
 		case TokenText:
 			node.Insert(New(Text, TextData{token.Raw}))
 			return parseNode, node, nil
@@ -49,7 +130,17 @@ func parseNode(node *Node, l lexer) (parseFunc, *Node, error) {
 			node.Insert(New(Single, nil))
 			return parseNode, node, nil
 		case TokenRangeOpen:
-			return parseRange, node, nil
+			return parseRange(node, l, token)
+		case TokenSequence:
+			d, ok := parseSequence(token.Raw)
+			if !ok {
+				if err := l.fail(errorf(token, ErrInvalidSequence, "invalid sequence %q", token.Raw)); err != nil {
+					return nil, node, err
+				}
+				return parseNode, node, nil
+			}
+			node.Insert(New(Sequence, d))
+			return parseNode, node, nil
 		case TokenTermsOpen:
 			n := New(AnyOf, nil)
 			node.Insert(n)
@@ -57,71 +148,132 @@ func parseNode(node *Node, l lexer) (parseFunc, *Node, error) {
 			n.Insert(p)
 			return parseNode, p, nil
 		case TokenSeparator:
+			if err := l.emptyAlt(node, token); err != nil {
+				return nil, node, err
+			}
 			n := New(Pattern, nil)
 			node.Parent.Insert(n)
 			return parseNode, n, nil
-		case TokenTermsClose:
+		case TokenTermsClose, TokenExtClose:
+			if err := l.emptyAlt(node, token); err != nil {
+				return nil, node, err
+			}
 			return parseNode, node.Parent.Parent, nil
+		case TokenExtOpen:
+			n := New(extTypes[token.Raw[0]], nil)
+			node.Insert(n)
+			p := New(Pattern, nil)
+			n.Insert(p)
+			return parseNode, p, nil
 		default:
-			return nil, node, fmt.Errorf("unexpected token: %s", token)
+			if err := l.fail(errorf(token, ErrUnexpectedToken, "unexpected token: %s", token)); err != nil {
+				return nil, node, err
+			}
 		}
 	}
 }
 
-func parseRange(node *Node, l lexer) (parseFunc, *Node, error) {
+// extTypes are the node types of the extended pattern list operators.
+var extTypes = map[byte]Type{
+	'?': ZeroOrOne,
+	'*': ZeroOrMore,
+	'+': OneOrMore,
+	'@': ExactlyOne,
+	'!': Not,
+}
+
+// parseRange parses the bracket expression opened by the token. When
+// recovering, invalid items are left out of the expression, and an empty
+// expression matches nothing (or with `!`, any character).
+func parseRange(node *Node, l *parser, open Token) (parseFunc, *Node, error) {
 	var (
-		not   bool
-		lo    rune
-		hi    rune
-		chars string
+		not     bool
+		lo      rune
+		loTok   Token
+		chars   string
+		items   []*Node
+		invalid bool
 	)
 	for {
 		token := l.Next()
 		switch token.Token {
 		case TokenEOF:
-			return nil, node, errors.New("unexpected end")
+			return nil, node, l.fail(errorf(open, ErrMissingBracket, "missing closing %c", char_range_close))
 		case TokenError:
-			return nil, node, errors.New(token.Raw)
+			return nil, node, l.fail(&Error{Offset: token.Offset, Msg: token.Raw})
 		case TokenNot:
 			not = true
 		case TokenRangeLo:
 			r, w := utf8.DecodeRuneInString(token.Raw)
 			if len(token.Raw) > w {
-				return nil, node, fmt.Errorf("unexpected length of lo character")
+				return nil, node, errorf(token, ErrInvalidRange, "unexpected length of lo character")
 			}
-			lo = r
+			lo, loTok = r, token
 		case TokenRangeBetween:
 			//
 		case TokenRangeHi:
-			r, w := utf8.DecodeRuneInString(token.Raw)
+			hi, w := utf8.DecodeRuneInString(token.Raw)
 			if len(token.Raw) > w {
-				return nil, node, fmt.Errorf("unexpected length of lo character")
+				return nil, node, errorf(token, ErrInvalidRange, "unexpected length of hi character")
 			}
-			hi = r
 			if hi < lo {
-				return nil, node, fmt.Errorf("hi character '%s' should be greater than lo '%s'", string(hi), string(lo))
+				if err := l.fail(errorf(loTok, ErrInvalidRange, "hi character '%s' should be greater than lo '%s'", string(hi), string(lo))); err != nil {
+					return nil, node, err
+				}
+				invalid = true
+				continue
 			}
+			items = append(items, New(Range, RangeData{Lo: lo, Hi: hi}))
 		case TokenText:
-			chars = token.Raw
+			chars += token.Raw
+		case TokenRangeClass:
+			// `P{name}` is the negated Unicode property class `p{name}`
+			name, negated := token.Raw, strings.HasPrefix(token.Raw, "P{")
+			if negated {
+				name = "p" + name[1:]
+			}
+			if ClassTables(name) == nil {
+				if err := l.fail(errorf(token, ErrInvalidClass, "unknown character class %q", token.Raw)); err != nil {
+					return nil, node, err
+				}
+				invalid = true
+				continue
+			}
+			items = append(items, New(Class, ClassData{Name: name, Not: negated}))
 		case TokenRangeClose:
-			isRange := lo != 0 && hi != 0
-			isChars := chars != ""
-			if isChars == isRange {
-				return nil, node, fmt.Errorf("could not parse range")
+			if chars != "" {
+				items = append([]*Node{New(List, ListData{Chars: chars})}, items...)
 			}
-			if isRange {
-				node.Insert(New(Range, RangeData{
-					Lo:  lo,
-					Hi:  hi,
-					Not: not,
-				}))
-			} else {
-				node.Insert(New(List, ListData{
-					Chars: chars,
-					Not:   not,
-				}))
+			switch len(items) {
+			case 0:
+				if !invalid {
+					if err := l.fail(errorf(open, ErrEmptyClass, "empty character class")); err != nil {
+						return nil, node, err
+					}
+				}
+				node.Insert(New(List, ListData{Not: not}))
+			case 1:
+				node.Insert(negate(items[0], not))
+			default:
+				node.Insert(New(CharSet, CharSetData{Not: not}, items...))
 			}
 			return parseNode, node, nil
 		}
 	}
 }
+
+// negate negates the single character node when not is true.
+func negate(n *Node, not bool) *Node {
+	switch v := n.Value.(type) {
+	case ListData:
+		v.Not = v.Not != not
+		n.Value = v
+	case RangeData:
+		v.Not = v.Not != not
+		n.Value = v
+	case ClassData:
+		v.Not = v.Not != not
+		n.Value = v
+	}
+	return n
+}
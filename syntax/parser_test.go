@@ -1,6 +1,7 @@
 package syntax
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
 	"testing"
@@ -14,8 +15,8 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "abc",
 			tokens: []Token{
-				{TokenText, "abc"},
-				{TokenEOF, ""},
+				{TokenText, "abc", 0},
+				{TokenEOF, "", 3},
 			},
 			exp: New(Pattern, nil,
 				New(Text, TextData{Text: "abc"}),
@@ -24,10 +25,10 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "a*c",
 			tokens: []Token{
-				{TokenText, "a"},
-				{TokenAny, "*"},
-				{TokenText, "c"},
-				{TokenEOF, ""},
+				{TokenText, "a", 0},
+				{TokenAny, "*", 1},
+				{TokenText, "c", 2},
+				{TokenEOF, "", 3},
 			},
 			exp: New(Pattern, nil,
 				New(Text, TextData{Text: "a"}),
@@ -38,10 +39,10 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "a**c",
 			tokens: []Token{
-				{TokenText, "a"},
-				{TokenSuper, "**"},
-				{TokenText, "c"},
-				{TokenEOF, ""},
+				{TokenText, "a", 0},
+				{TokenSuper, "**", 1},
+				{TokenText, "c", 3},
+				{TokenEOF, "", 4},
 			},
 			exp: New(Pattern, nil,
 				New(Text, TextData{Text: "a"}),
@@ -52,10 +53,10 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "a?c",
 			tokens: []Token{
-				{TokenText, "a"},
-				{TokenSingle, "?"},
-				{TokenText, "c"},
-				{TokenEOF, ""},
+				{TokenText, "a", 0},
+				{TokenSingle, "?", 1},
+				{TokenText, "c", 2},
+				{TokenEOF, "", 3},
 			},
 			exp: New(Pattern, nil,
 				New(Text, TextData{Text: "a"}),
@@ -66,13 +67,13 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "[!a-z]",
 			tokens: []Token{
-				{TokenRangeOpen, "["},
-				{TokenNot, "!"},
-				{TokenRangeLo, "a"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "z"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenRangeLo, "a", 2},
+				{TokenRangeBetween, "-", 3},
+				{TokenRangeHi, "z", 4},
+				{TokenRangeClose, "]", 5},
+				{TokenEOF, "", 6},
 			},
 			exp: New(Pattern, nil,
 				New(Range, RangeData{Lo: 'a', Hi: 'z', Not: true}),
@@ -81,10 +82,10 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "[az]",
 			tokens: []Token{
-				{TokenRangeOpen, "["},
-				{TokenText, "az"},
-				{TokenRangeClose, "]"},
-				{TokenEOF, ""},
+				{TokenRangeOpen, "[", 0},
+				{TokenText, "az", 1},
+				{TokenRangeClose, "]", 3},
+				{TokenEOF, "", 4},
 			},
 			exp: New(Pattern, nil,
 				New(List, ListData{Chars: "az"}),
@@ -93,12 +94,12 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "{a,z}",
 			tokens: []Token{
-				{TokenTermsOpen, "{"},
-				{TokenText, "a"},
-				{TokenSeparator, ","},
-				{TokenText, "z"},
-				{TokenTermsClose, "}"},
-				{TokenEOF, ""},
+				{TokenTermsOpen, "{", 0},
+				{TokenText, "a", 1},
+				{TokenSeparator, ",", 2},
+				{TokenText, "z", 3},
+				{TokenTermsClose, "}", 4},
+				{TokenEOF, "", 5},
 			},
 			exp: New(Pattern, nil,
 				New(AnyOf, nil,
@@ -114,14 +115,14 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "/{z,ab}*",
 			tokens: []Token{
-				{TokenText, "/"},
-				{TokenTermsOpen, "{"},
-				{TokenText, "z"},
-				{TokenSeparator, ","},
-				{TokenText, "ab"},
-				{TokenTermsClose, "}"},
-				{TokenAny, "*"},
-				{TokenEOF, ""},
+				{TokenText, "/", 0},
+				{TokenTermsOpen, "{", 1},
+				{TokenText, "z", 2},
+				{TokenSeparator, ",", 3},
+				{TokenText, "ab", 4},
+				{TokenTermsClose, "}", 6},
+				{TokenAny, "*", 7},
+				{TokenEOF, "", 8},
 			},
 			exp: New(Pattern, nil,
 				New(Text, TextData{Text: "/"}),
@@ -139,29 +140,29 @@ func TestParse(t *testing.T) {
 		{
 			// pattern: "{a,{x,y},?,[a-z],[!qwe]}",
 			tokens: []Token{
-				{TokenTermsOpen, "{"},
-				{TokenText, "a"},
-				{TokenSeparator, ","},
-				{TokenTermsOpen, "{"},
-				{TokenText, "x"},
-				{TokenSeparator, ","},
-				{TokenText, "y"},
-				{TokenTermsClose, "}"},
-				{TokenSeparator, ","},
-				{TokenSingle, "?"},
-				{TokenSeparator, ","},
-				{TokenRangeOpen, "["},
-				{TokenRangeLo, "a"},
-				{TokenRangeBetween, "-"},
-				{TokenRangeHi, "z"},
-				{TokenRangeClose, "]"},
-				{TokenSeparator, ","},
-				{TokenRangeOpen, "["},
-				{TokenNot, "!"},
-				{TokenText, "qwe"},
-				{TokenRangeClose, "]"},
-				{TokenTermsClose, "}"},
-				{TokenEOF, ""},
+				{TokenTermsOpen, "{", 0},
+				{TokenText, "a", 1},
+				{TokenSeparator, ",", 2},
+				{TokenTermsOpen, "{", 3},
+				{TokenText, "x", 4},
+				{TokenSeparator, ",", 5},
+				{TokenText, "y", 6},
+				{TokenTermsClose, "}", 7},
+				{TokenSeparator, ",", 8},
+				{TokenSingle, "?", 9},
+				{TokenSeparator, ",", 10},
+				{TokenRangeOpen, "[", 11},
+				{TokenRangeLo, "a", 12},
+				{TokenRangeBetween, "-", 13},
+				{TokenRangeHi, "z", 14},
+				{TokenRangeClose, "]", 15},
+				{TokenSeparator, ",", 16},
+				{TokenRangeOpen, "[", 17},
+				{TokenNot, "!", 18},
+				{TokenText, "qwe", 19},
+				{TokenRangeClose, "]", 22},
+				{TokenTermsClose, "}", 23},
+				{TokenEOF, "", 24},
 			},
 			exp: New(Pattern, nil,
 				New(AnyOf, nil,
@@ -190,6 +191,40 @@ func TestParse(t *testing.T) {
 				),
 			),
 		},
+		{
+			// pattern: "[![:digit:]]",
+			tokens: []Token{
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenRangeClass, "digit", 2},
+				{TokenRangeClose, "]", 11},
+				{TokenEOF, "", 12},
+			},
+			exp: New(Pattern, nil,
+				New(Class, ClassData{Name: "digit", Not: true}),
+			),
+		},
+		{
+			// pattern: "[!a-z_[:digit:]]",
+			tokens: []Token{
+				{TokenRangeOpen, "[", 0},
+				{TokenNot, "!", 1},
+				{TokenRangeLo, "a", 2},
+				{TokenRangeBetween, "-", 3},
+				{TokenRangeHi, "z", 4},
+				{TokenText, "_", 5},
+				{TokenRangeClass, "digit", 6},
+				{TokenRangeClose, "]", 15},
+				{TokenEOF, "", 16},
+			},
+			exp: New(Pattern, nil,
+				New(CharSet, CharSetData{Not: true},
+					New(List, ListData{Chars: "_"}),
+					New(Range, RangeData{Lo: 'a', Hi: 'z'}),
+					New(Class, ClassData{Name: "digit"}),
+				),
+			),
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			l := &stubLexer{tokens: test.tokens}
@@ -204,6 +239,129 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseError(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		flags   Flags
+		code    ErrorCode
+		offset  int
+	}{
+		{"[[:bogus:]]", 0, ErrInvalidClass, 1},
+		{"[[:alpha:]", 0, ErrMissingBracket, 0},
+		{"a[z-a]", 0, ErrInvalidRange, 2},
+		{`[\p{Bogus}]`, 0, ErrInvalidClass, 1},
+		{"ab[]", 0, ErrEmptyClass, 2},
+		{"a{b,[!]}", 0, ErrEmptyClass, 4},
+		{"a[bc", 0, ErrMissingBracket, 1},
+		{"a[b-", 0, ErrMissingBracket, 1},
+		{`a[b\`, 0, ErrTrailingEscape, 3},
+		{`a[b\`, NoEscape, ErrMissingBracket, 1},
+		{"日本[語", 0, ErrMissingBracket, 6},
+		{"a\xffb", 0, ErrInvalidUTF8, 1},
+		{"a{b,c", Strict, ErrMissingBrace, 1},
+		{"{a,+(b", Strict | ExtGlob, ErrMissingParen, 3},
+		{"a}b", Strict, ErrUnexpectedBrace, 1},
+		{"{a,b]}", Strict, ErrUnexpectedBracket, 4},
+		{`ab\`, Strict, ErrTrailingEscape, 2},
+		{"{a,}", Strict, ErrEmptyAlt, 3},
+		{"{,a}", Strict, ErrEmptyAlt, 1},
+		{"x{}", Strict, ErrEmptyAlt, 2},
+		{"{a,,b}", Strict, ErrEmptyAlt, 3},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := Parse(NewLexerFlags(test.pattern, test.flags))
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *Error, got: %v", err)
+			}
+			if e.Pattern != test.pattern {
+				t.Errorf("expected pattern %q, got: %q", test.pattern, e.Pattern)
+			}
+			if e.Code != test.code {
+				t.Errorf("expected code %q, got: %q", test.code, e.Code)
+			}
+			if e.Offset != test.offset {
+				t.Errorf("expected offset %d, got: %d", test.offset, e.Offset)
+			}
+		})
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	for i, pattern := range []string{
+		"{a,b}",
+		"{1..3}",
+		`a\}\]`,
+		"+(a|)",
+		"{a,+(b)}c",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			exp, err := Parse(NewLexerFlags(pattern, ExtGlob))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			tree, err := Parse(NewLexerFlags(pattern, ExtGlob|Strict))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(tree, exp) {
+				t.Errorf("expected:\n%v\ngot:\n%v", exp, tree)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		flags   Flags
+		codes   []ErrorCode
+		offsets []int
+	}{
+		{"a*{b,c}", 0, nil, nil},
+		{"{a,b", 0, []ErrorCode{ErrMissingBrace}, []int{0}},
+		{"a[]b", 0, []ErrorCode{ErrEmptyClass}, []int{1}},
+		{"[!]", 0, []ErrorCode{ErrEmptyClass}, []int{0}},
+		{"a[z-a]", 0, []ErrorCode{ErrInvalidRange}, []int{2}},
+		{`ab\`, 0, []ErrorCode{ErrTrailingEscape}, []int{2}},
+		{`ab\`, NoEscape, nil, nil},
+		{"a[b", 0, []ErrorCode{ErrMissingBracket}, []int{1}},
+		{`a[b\`, 0, []ErrorCode{ErrMissingBracket, ErrTrailingEscape}, []int{1, 3}},
+		{"[[:bogus:]x-a]", 0, []ErrorCode{ErrInvalidClass, ErrInvalidRange}, []int{1, 10}},
+		{"{a,[c-a],[]}", 0, []ErrorCode{ErrInvalidRange, ErrEmptyClass}, []int{4, 9}},
+		{"x{a,+(b", ExtGlob, []ErrorCode{ErrMissingBrace, ErrMissingParen}, []int{1, 4}},
+		{"a\xffb[", 0, []ErrorCode{ErrInvalidUTF8, ErrMissingBracket}, []int{1, 3}},
+		{"a}b]{c,}", 0, []ErrorCode{ErrUnexpectedBrace, ErrUnexpectedBracket, ErrEmptyAlt}, []int{1, 3, 7}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, errs := ParseAll(NewLexerFlags(test.pattern, test.flags))
+			if tree == nil {
+				t.Fatalf("expected tree, got nil")
+			}
+			if _, err := tree.MatchFlags(nil, test.flags); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+			var codes []ErrorCode
+			var offsets []int
+			for _, e := range errs {
+				if e.Pattern != test.pattern {
+					t.Errorf("expected pattern %q, got: %q", test.pattern, e.Pattern)
+				}
+				codes, offsets = append(codes, e.Code), append(offsets, e.Offset)
+			}
+			if !reflect.DeepEqual(codes, test.codes) {
+				t.Errorf("expected codes %q, got: %q", test.codes, codes)
+			}
+			if !reflect.DeepEqual(offsets, test.offsets) {
+				t.Errorf("expected offsets %v, got: %v", test.offsets, offsets)
+			}
+			if exp, err := Parse(NewLexerFlags(test.pattern, test.flags)); err == nil && !reflect.DeepEqual(tree, exp) {
+				t.Errorf("expected:\n%v\ngot:\n%v", exp, tree)
+			}
+		})
+	}
+}
+
 type stubLexer struct {
 	tokens []Token
 	pos    int
@@ -211,7 +369,7 @@ type stubLexer struct {
 
 func (s *stubLexer) Next() (ret Token) {
 	if s.pos == len(s.tokens) {
-		return Token{TokenEOF, ""}
+		return Token{Token: TokenEOF}
 	}
 	ret = s.tokens[s.pos]
 	s.pos++
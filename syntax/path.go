@@ -0,0 +1,118 @@
+package syntax
+
+import (
+	"path"
+	"unicode/utf8"
+)
+
+// ParsePath parses a pattern with the syntax of [path.Match]:
+//
+//	`*`          matches any sequence of non-`/` characters
+//	`?`          matches any single non-`/` character
+//	`[` [ `^` ] { character-range } `]`
+//	             matches any single character in (or not in) the ranges
+//	`\` c        matches character c
+//
+// where a character-range is c or lo `-` hi. There is no `**`, no `!`
+// negation and no `{...}` alternatives. Returns [path.ErrBadPattern] for
+// exactly the patterns that [path.Match] reports as malformed. With the
+// NoEscape flag, `\` is a regular character, as with [path/filepath.Match] on
+// Windows.
+func ParsePath(pattern string, flags Flags) (*Node, error) {
+	tree := New(Pattern, nil)
+	var text []byte
+	flush := func() {
+		if len(text) != 0 {
+			tree.Insert(New(Text, TextData{string(text)}))
+			text = nil
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*':
+			flush()
+			if n := len(tree.Children); n == 0 || tree.Children[n-1].Type != Any {
+				tree.Insert(New(Any, nil))
+			}
+		case c == '?':
+			flush()
+			tree.Insert(New(Single, nil))
+		case c == '[':
+			flush()
+			n, w, err := parsePathRange(pattern[i+1:], flags)
+			if err != nil {
+				return nil, err
+			}
+			tree.Insert(n)
+			i += w
+		case c == '\\' && flags&NoEscape == 0:
+			if i++; i == len(pattern) {
+				return nil, path.ErrBadPattern
+			}
+			text = append(text, pattern[i])
+		default:
+			text = append(text, c)
+		}
+	}
+	flush()
+	return tree, nil
+}
+
+// parsePathRange parses the body of a [path.Match] character class following
+// its `[`, returning the node and the length of the body, including the
+// closing `]`.
+func parsePathRange(s string, flags Flags) (*Node, int, error) {
+	var i int
+	not := len(s) != 0 && s[0] == '^'
+	if not {
+		i++
+	}
+	// char reads a character of a range, which must not be the last character
+	// of the pattern
+	char := func() (rune, error) {
+		if i == len(s) || s[i] == '-' || s[i] == ']' {
+			return 0, path.ErrBadPattern
+		}
+		if s[i] == '\\' && flags&NoEscape == 0 {
+			if i++; i == len(s) {
+				return 0, path.ErrBadPattern
+			}
+		}
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if i += w; r == utf8.RuneError && w == 1 || i == len(s) {
+			return 0, path.ErrBadPattern
+		}
+		return r, nil
+	}
+	var (
+		chars string
+		items []*Node
+	)
+	for n := 0; n == 0 || s[i] != ']'; n++ {
+		lo, err := char()
+		if err != nil {
+			return nil, 0, err
+		}
+		hi := lo
+		if s[i] == '-' {
+			i++
+			if hi, err = char(); err != nil {
+				return nil, 0, err
+			}
+		}
+		// ranges with hi less than lo match nothing
+		switch {
+		case lo == hi:
+			chars += string(lo)
+		case lo < hi:
+			items = append(items, New(Range, RangeData{Lo: lo, Hi: hi}))
+		}
+	}
+	if chars != "" || len(items) == 0 {
+		items = append([]*Node{New(List, ListData{Chars: chars})}, items...)
+	}
+	if len(items) == 1 {
+		return negate(items[0], not), i + 1, nil
+	}
+	return New(CharSet, CharSetData{Not: not}, items...), i + 1, nil
+}
@@ -0,0 +1,383 @@
+package syntax
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ToRegexp translates the tree into an equivalent RE2 pattern (see
+// [regexp/syntax]) matching the whole of the input. Separators are excluded
+// from the characters matched by `*` and `?`.
+//
+// Negated pattern lists (`!(...)`) have no RE2 equivalent, and are translated
+// to a negative lookahead, which is rejected by [regexp.Compile]. Returns an
+// error for brace sequences that would need more than
+// [MaxRegexpSequence] alternatives, such as `{0..1000000..3}`.
+func ToRegexp(node *Node, sep []rune) (string, error) {
+	b := regexpBuilder{sep: sep}
+	b.node(node)
+	if b.err != nil {
+		return "", b.err
+	}
+	s := "^" + b.String() + "$"
+	if b.dot {
+		s = "(?s)" + s
+	}
+	return s, nil
+}
+
+// MaxRegexpSequence is the maximum number of values of a stepped or character
+// brace sequence translated by [ToRegexp], each of which is an alternative of
+// the pattern.
+const MaxRegexpSequence = 10000
+
+// regexpBuilder builds a RE2 pattern for a tree.
+type regexpBuilder struct {
+	strings.Builder
+	sep []rune
+	dot bool
+	err error
+}
+
+// node writes the pattern for the node.
+func (b *regexpBuilder) node(n *Node) {
+	switch n.Type {
+	case Pattern:
+		for _, c := range n.Children {
+			b.node(c)
+		}
+	case Text:
+		b.WriteString(regexp.QuoteMeta(n.Value.(TextData).Text))
+	case Any:
+		b.single()
+		b.WriteByte('*')
+	case Super:
+		b.dot = true
+		b.WriteString(".*")
+	case Single:
+		b.single()
+	case List, Range, Class, CharSet:
+		b.WriteString(charClass(n))
+	case AnyOf, ExactlyOne:
+		b.alt(n, "")
+	case ZeroOrOne:
+		b.alt(n, "?")
+	case ZeroOrMore:
+		b.alt(n, "*")
+	case OneOrMore:
+		b.alt(n, "+")
+	case Not:
+		b.WriteString("(?!")
+		b.alt(n, "")
+		b.WriteByte(')')
+		b.single()
+		b.WriteByte('*')
+	case Sequence:
+		s, err := sequenceRegexp(n.Value.(SequenceData))
+		if err != nil && b.err == nil {
+			b.err = err
+		}
+		b.WriteString(s)
+	}
+}
+
+// single writes the pattern for a single non-separator character.
+func (b *regexpBuilder) single() {
+	if len(b.sep) == 0 {
+		b.dot = true
+		b.WriteByte('.')
+		return
+	}
+	b.WriteString("[^")
+	for _, r := range b.sep {
+		b.WriteString(classRune(r))
+	}
+	b.WriteByte(']')
+}
+
+// alt writes a group of the alternatives of the node, followed by op.
+func (b *regexpBuilder) alt(n *Node, op string) {
+	b.WriteString("(?:")
+	for i, c := range n.Children {
+		if i != 0 {
+			b.WriteByte('|')
+		}
+		b.node(c)
+	}
+	b.WriteString(")" + op)
+}
+
+// charClass returns the character class for a single character node.
+func charClass(n *Node) string {
+	var not bool
+	var s string
+	switch v := n.Value.(type) {
+	case ListData:
+		for _, r := range v.Chars {
+			s += classRune(r)
+		}
+		not = v.Not
+	case RangeData:
+		s, not = classRune(v.Lo)+"-"+classRune(v.Hi), v.Not
+	case ClassData:
+		s, not = classRegexp(v.Name, false), v.Not
+	case CharSetData:
+		for _, c := range n.Children {
+			if c.Type == Class {
+				d := c.Value.(ClassData)
+				s += classRegexp(d.Name, d.Not)
+			} else {
+				v := charClass(c)
+				s += v[1 : len(v)-1]
+			}
+		}
+		not = v.Not
+	}
+	switch {
+	case s == "" && not:
+		// empty sets only occur with [ParsePath]
+		return `[\x00-\x{10ffff}]`
+	case s == "":
+		return `[^\x00-\x{10ffff}]`
+	case not:
+		return "[^" + s + "]"
+	}
+	return "[" + s + "]"
+}
+
+// regexpClasses are the RE2 classes for the POSIX character classes, where
+// RE2 has an equivalent.
+var regexpClasses = map[string]string{
+	"alnum": `\pL\p{Nd}`,
+	"alpha": `\pL`,
+	"blank": `\p{Zs}\t`,
+	"cntrl": `\p{Cc}`,
+	"digit": `\p{Nd}`,
+	"graph": `\pL\pM\pN\pP\pS`,
+	"lower": `\p{Ll}`,
+	"print": `\pL\pM\pN\pP\pS\p{Zs}`,
+	"punct": `\pP\pS`,
+	"upper": `\p{Lu}`,
+}
+
+// classRegexp returns the contents of a RE2 character class for the named
+// character class, or its complement when not is true.
+func classRegexp(name string, not bool) string {
+	if s, ok := strings.CutPrefix(name, "p{"); ok {
+		s = strings.TrimSuffix(s, "}")
+		if unicode.Categories[s] != nil || unicode.Scripts[s] != nil {
+			if not {
+				return `\P{` + s + `}`
+			}
+			return `\p{` + s + `}`
+		}
+	}
+	if s, ok := regexpClasses[name]; ok && !not {
+		return s
+	}
+	var v [][2]rune
+	for _, t := range ClassTables(name) {
+		v = append(v, tableRanges(t)...)
+	}
+	v = mergeRanges(v)
+	if not {
+		v = complementRanges(v)
+	}
+	var s string
+	for _, r := range v {
+		s += classRune(r[0])
+		if r[1] != r[0] {
+			s += "-" + classRune(r[1])
+		}
+	}
+	return s
+}
+
+// tableRanges returns the ranges of the table.
+func tableRanges(t *unicode.RangeTable) [][2]rune {
+	var v [][2]rune
+	add := func(lo, hi, stride uint32) {
+		if stride == 1 {
+			v = append(v, [2]rune{rune(lo), rune(hi)})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			v = append(v, [2]rune{rune(r), rune(r)})
+		}
+	}
+	for _, r := range t.R16 {
+		add(uint32(r.Lo), uint32(r.Hi), uint32(r.Stride))
+	}
+	for _, r := range t.R32 {
+		add(r.Lo, r.Hi, r.Stride)
+	}
+	return v
+}
+
+// mergeRanges sorts and merges overlapping and adjacent ranges.
+func mergeRanges(v [][2]rune) [][2]rune {
+	slices.SortFunc(v, func(a, b [2]rune) int {
+		return int(a[0] - b[0])
+	})
+	var m [][2]rune
+	for _, r := range v {
+		if n := len(m); n != 0 && r[0] <= m[n-1][1]+1 {
+			m[n-1][1] = max(m[n-1][1], r[1])
+			continue
+		}
+		m = append(m, r)
+	}
+	return m
+}
+
+// complementRanges returns the complement of the sorted, merged ranges.
+func complementRanges(v [][2]rune) [][2]rune {
+	var c [][2]rune
+	next := rune(0)
+	for _, r := range v {
+		if next < r[0] {
+			c = append(c, [2]rune{next, r[0] - 1})
+		}
+		next = r[1] + 1
+	}
+	if next <= unicode.MaxRune {
+		c = append(c, [2]rune{next, unicode.MaxRune})
+	}
+	return c
+}
+
+// classRune returns the rune escaped for use in a RE2 character class.
+func classRune(r rune) string {
+	switch {
+	case strings.ContainsRune(`\]-^[`, r):
+		return `\` + string(r)
+	case !unicode.IsPrint(r):
+		return `\x{` + strconv.FormatInt(int64(r), 16) + `}`
+	}
+	return string(r)
+}
+
+// sequenceRegexp returns the pattern for the brace sequence.
+func sequenceRegexp(d SequenceData) (string, error) {
+	lo, hi := min(d.Start, d.End), max(d.Start, d.End)
+	switch {
+	case d.Chars && d.Step == 1:
+		return "[" + classRune(rune(lo)) + "-" + classRune(rune(hi)) + "]", nil
+	case (d.Chars || d.Step != 1) && d.Len() > MaxRegexpSequence:
+		return "", fmt.Errorf("cannot translate sequence of more than %d values", MaxRegexpSequence)
+	case d.Chars || d.Step != 1:
+		v := make([]string, d.Len())
+		for i := range v {
+			v[i] = regexp.QuoteMeta(d.Value(i))
+		}
+		return "(?:" + strings.Join(v, "|") + ")", nil
+	}
+	var v []string
+	if lo < 0 {
+		// negative values are the positive values with a `-` prefix, padded
+		// to one less than the width
+		start := uint64(1)
+		if hi < 0 {
+			start = abs(hi)
+		}
+		for _, s := range intRangeRegexp(start, abs(lo), max(d.Width-1, 0)) {
+			v = append(v, "-"+s)
+		}
+	}
+	if hi >= 0 {
+		v = append(v, intRangeRegexp(uint64(max(lo, 0)), uint64(hi), d.Width)...)
+	}
+	return "(?:" + strings.Join(v, "|") + ")", nil
+}
+
+// intRangeRegexp returns the alternatives matching the non-negative integers
+// from lo to hi, zero padded to the width.
+func intRangeRegexp(lo, hi uint64, width int) []string {
+	if width != 0 {
+		return digitRangeRegexp(padUint(lo, width), padUint(hi, width))
+	}
+	var v []string
+	// split the range by number of digits, of which a uint64 has at most 20
+	for n := 1; ; n++ {
+		end := hi
+		if n < 20 {
+			end = min(hi, pow10(n)-1)
+		}
+		if lo <= end {
+			v = append(v, digitRangeRegexp(strconv.FormatUint(lo, 10), strconv.FormatUint(end, 10))...)
+			if end == hi {
+				return v
+			}
+			lo = end + 1
+		}
+	}
+}
+
+// padUint formats u, zero padded to the width.
+func padUint(u uint64, width int) string {
+	s := strconv.FormatUint(u, 10)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
+
+// digitRangeRegexp returns the alternatives matching the strings of digits
+// from a to b, which have the same length.
+func digitRangeRegexp(a, b string) []string {
+	switch {
+	case a == b:
+		return []string{a}
+	case a[0] == b[0]:
+		v := digitRangeRegexp(a[1:], b[1:])
+		for i := range v {
+			v[i] = a[:1] + v[i]
+		}
+		return v
+	}
+	n := len(a) - 1
+	lo, hi := a[0], b[0]
+	var v []string
+	if strings.Trim(a[1:], "0") != "" {
+		for _, s := range digitRangeRegexp(a[1:], strings.Repeat("9", n)) {
+			v = append(v, a[:1]+s)
+		}
+		lo++
+	}
+	last := strings.Trim(b[1:], "9") != ""
+	if last {
+		hi--
+	}
+	if lo <= hi {
+		s := string(lo)
+		if lo != hi {
+			s = "[" + string(lo) + "-" + string(hi) + "]"
+		}
+		if n != 0 {
+			s += `\d`
+			if n != 1 {
+				s += "{" + strconv.Itoa(n) + "}"
+			}
+		}
+		v = append(v, s)
+	}
+	if last {
+		for _, s := range digitRangeRegexp(strings.Repeat("0", n), b[1:]) {
+			v = append(v, b[:1]+s)
+		}
+	}
+	return v
+}
+
+// pow10 returns 10 to the n'th power.
+func pow10(n int) uint64 {
+	v := uint64(1)
+	for range n {
+		v *= 10
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package syntax
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestToRegexp(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		sep     []rune
+		exp     string
+	}{
+		{`abc`, nil, `^abc$`},
+		{`a.b`, nil, `^a\.b$`},
+		{`*.go`, []rune{'/'}, `^[^/]*\.go$`},
+		{`*.go`, nil, `(?s)^.*\.go$`},
+		{`src/**/?.go`, []rune{'/'}, `(?s)^src/.*/[^/]\.go$`},
+		{`[a-c][!xyz][\]\-]`, nil, `^[a-c][^xyz][\]\-]$`},
+		{`[[:digit:]_a-f][![:alpha:]]`, nil, `^[_\p{Nd}a-f][^\pL]$`},
+		{`[\p{Han}\P{Greek}]`, nil, `^[\p{Han}\P{Greek}]$`},
+		{`[[:xdigit:]]`, nil, `^[0-9A-Fa-f]$`},
+		{`{a,b*}c`, []rune{'.'}, `^(?:a|b[^.]*)c$`},
+		{`log.{1..30}`, nil, `^log\.(?:[1-9]|[1-2]\d|30)$`},
+		{`{01..12}`, nil, `^(?:0[1-9]|1[0-2])$`},
+		{`{a..f}{1..7..3}`, nil, `^[a-f](?:1|4|7)$`},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexer(test.pattern))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			s, err := ToRegexp(tree, test.sep)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s != test.exp {
+				t.Errorf("expected %s, got: %s", test.exp, s)
+			}
+			if _, err := regexp.Compile(s); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestSequenceRegexp(t *testing.T) {
+	for i, test := range []string{
+		"0..0",
+		"0..9",
+		"1..30",
+		"7..1234",
+		"95..105",
+		"100..999",
+		"199..2001",
+		"0..1000",
+		"01..12",
+		"007..100",
+		"-12..-3",
+		"-5..17",
+		"-05..05",
+		"-100..-1",
+		"20..3",
+		"-9223372036854775807..9223372036854775807",
+		"-9223372036854775808..-9223372036854775800",
+		"-09223372036854775808..9223372036854775807",
+		"9223372036854775800..18",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			d, ok := parseSequence(test)
+			if !ok {
+				t.Fatalf("expected sequence %q to parse", test)
+			}
+			s, err := sequenceRegexp(d)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			re := regexp.MustCompile("^" + s + "$")
+			values := []int{math.MinInt, math.MinInt + 1, math.MinInt + 8, math.MinInt + 9, math.MaxInt - 1, math.MaxInt}
+			for n := -1500; n <= 2500; n++ {
+				values = append(values, n)
+			}
+			for _, n := range values {
+				for _, s := range []string{strconv.Itoa(n), d.format(n)} {
+					if a, b := re.MatchString(s), d.Contains(s); a != b {
+						t.Fatalf("%q: expected %t, got: %t (%s)", s, b, a, re)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestToRegexpError(t *testing.T) {
+	for i, pattern := range []string{
+		"{0..9223372036854775807..3}",
+		"{-9223372036854775807..9223372036854775807..2}",
+		"{a..􏿿..2}",
+		"x{1..20001..2}",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexer(pattern))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if _, err := ToRegexp(tree, nil); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
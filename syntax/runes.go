@@ -3,9 +3,17 @@ package syntax
 import (
 	"slices"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
+// runeWidth returns the width in bytes of the rune starting at s[i], which is
+// 1 for an invalid UTF-8 byte.
+func runeWidth(s string, i int) int {
+	_, w := utf8.DecodeRuneInString(s[i:])
+	return w
+}
+
 func runesHead(s string, r int) string {
 	var i, m int
 	for i < len(s) {
@@ -20,21 +28,10 @@ func runesHead(s string, r int) string {
 }
 
 func runesTail(s string, r int) string {
-	var i, n int
-	for i = len(s); i >= 0; {
-		var ok bool
-		for j := 1; j <= 4 && i-j >= 0; j++ {
-			v, _ := utf8.DecodeRuneInString(s[i-j:])
-			if v != utf8.RuneError {
-				i -= j
-				n++
-				ok = true
-				break
-			}
-		}
-		if !ok || n == r {
-			return s[i:]
-		}
+	i := len(s)
+	for n := 0; n < r && i > 0; n++ {
+		_, w := utf8.DecodeLastRuneInString(s[:i])
+		i -= w
 	}
 	return s[i:]
 }
@@ -182,3 +179,112 @@ func runesEqual(a, b []rune) bool {
 	}
 	return false
 }
+
+// runesFoldEqual reports whether a and b are equal under Unicode simple case
+// folding.
+func runesFoldEqual(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// runesFold returns rs with all runes in the case folding orbit of each rune
+// added.
+func runesFold(rs []rune) []rune {
+	v := slices.Clone(rs)
+	for _, r := range rs {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if !slices.Contains(v, f) {
+				v = append(v, f)
+			}
+		}
+	}
+	return v
+}
+
+// textPrefix returns the number of bytes of s matching prefix, folding case
+// when fold is true. Returns -1 when s does not start with prefix.
+func textPrefix(s, prefix string, fold bool) int {
+	if !fold {
+		if strings.HasPrefix(s, prefix) {
+			return len(prefix)
+		}
+		return -1
+	}
+	var i int
+	for _, p := range prefix {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if w == 0 || !runesFoldEqual(r, p) {
+			return -1
+		}
+		i += w
+	}
+	return i
+}
+
+// textSuffix returns the number of bytes of s matching suffix, folding case
+// when fold is true. Returns -1 when s does not end with suffix.
+func textSuffix(s, suffix string, fold bool) int {
+	if !fold {
+		if strings.HasSuffix(s, suffix) {
+			return len(suffix)
+		}
+		return -1
+	}
+	i, j := len(s), len(suffix)
+	for j > 0 {
+		p, pw := utf8.DecodeLastRuneInString(suffix[:j])
+		r, w := utf8.DecodeLastRuneInString(s[:i])
+		if w == 0 || !runesFoldEqual(r, p) {
+			return -1
+		}
+		i, j = i-w, j-pw
+	}
+	return len(s) - i
+}
+
+// textIndex returns the index of the first instance of substr in s and the
+// number of bytes of s it spans, folding case when fold is true. Returns -1
+// when substr is not present in s.
+func textIndex(s, substr string, fold bool) (int, int) {
+	if !fold {
+		return strings.Index(s, substr), len(substr)
+	}
+	for i := 0; i <= len(s); {
+		if n := textPrefix(s[i:], substr, true); n != -1 {
+			return i, n
+		}
+		if i == len(s) {
+			break
+		}
+		_, w := utf8.DecodeRuneInString(s[i:])
+		i += w
+	}
+	return -1, 0
+}
+
+// textLastIndex returns the index of the last instance of substr in s and the
+// number of bytes of s it spans, folding case when fold is true. Returns -1
+// when substr is not present in s.
+func textLastIndex(s, substr string, fold bool) (int, int) {
+	if !fold {
+		return strings.LastIndex(s, substr), len(substr)
+	}
+	for i := len(s); i >= 0; {
+		if n := textPrefix(s[i:], substr, true); n != -1 {
+			return i, n
+		}
+		if i == 0 {
+			break
+		}
+		_, w := utf8.DecodeLastRuneInString(s[:i])
+		i -= w
+	}
+	return -1, 0
+}
@@ -221,3 +221,53 @@ func BenchmarkNotEqualStrings(b *testing.B) {
 		}
 	}
 }
+
+func TestTextFold(t *testing.T) {
+	for i, test := range []struct {
+		s, substr string
+		prefix    int
+		suffix    int
+		index     int
+	}{
+		{"ABC", "abc", 3, 3, 0},
+		{"xABCx", "abc", -1, -1, 1},
+		{"\u212aELVIN", "kelvin", 8, 8, 0},
+		{"ΣΑΣ", "σας", 6, 6, 0},
+		{"abd", "abc", -1, -1, -1},
+		{"ab", "abc", -1, -1, -1},
+	} {
+		if n := textPrefix(test.s, test.substr, true); n != test.prefix {
+			t.Errorf("test %d expected prefix %d, got: %d", i, test.prefix, n)
+		}
+		if n := textSuffix(test.s, test.substr, true); n != test.suffix {
+			t.Errorf("test %d expected suffix %d, got: %d", i, test.suffix, n)
+		}
+		if j, _ := textIndex(test.s, test.substr, true); j != test.index {
+			t.Errorf("test %d expected index %d, got: %d", i, test.index, j)
+		}
+	}
+}
+
+func TestRunesHeadTail(t *testing.T) {
+	for i, test := range []struct {
+		s    string
+		n    int
+		head string
+		tail string
+	}{
+		{"abc", 1, "a", "c"},
+		{"abc", 2, "ab", "bc"},
+		{"abc", 4, "abc", "abc"},
+		{"äbü", 1, "ä", "ü"},
+		{"-\xff", 1, "-", "\xff"},
+		{"\xff\xfe", 1, "\xff", "\xfe"},
+		{"ä�", 1, "ä", "�"},
+	} {
+		if s := runesHead(test.s, test.n); s != test.head {
+			t.Errorf("test %d expected head %q, got: %q", i, test.head, s)
+		}
+		if s := runesTail(test.s, test.n); s != test.tail {
+			t.Errorf("test %d expected tail %q, got: %q", i, test.tail, s)
+		}
+	}
+}
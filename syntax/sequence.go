@@ -0,0 +1,154 @@
+package syntax
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseSequence parses the body of a brace sequence (`x..y` or `x..y..incr`),
+// where x and y are both integers or both single characters.
+func parseSequence(s string) (SequenceData, bool) {
+	v := strings.Split(s, "..")
+	if len(v) != 2 && len(v) != 3 {
+		return SequenceData{}, false
+	}
+	d := SequenceData{Step: 1}
+	if len(v) == 3 {
+		step, err := strconv.Atoi(v[2])
+		if err != nil {
+			return SequenceData{}, false
+		}
+		d.Step = max(step, -step, 1)
+	}
+	start, err1 := strconv.Atoi(v[0])
+	end, err2 := strconv.Atoi(v[1])
+	switch {
+	case err1 == nil && err2 == nil:
+		d.Start, d.End = start, end
+		if zeroPadded(v[0]) || zeroPadded(v[1]) {
+			d.Width = max(len(v[0]), len(v[1]))
+		}
+		return d, true
+	case err1 != nil && err2 != nil &&
+		utf8.RuneCountInString(v[0]) == 1 && utf8.RuneCountInString(v[1]) == 1:
+		s, _ := utf8.DecodeRuneInString(v[0])
+		e, _ := utf8.DecodeRuneInString(v[1])
+		d.Start, d.End, d.Chars = int(s), int(e), true
+		return d, true
+	}
+	return SequenceData{}, false
+}
+
+// zeroPadded reports whether the integer has a leading zero.
+func zeroPadded(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return len(s) > 1 && s[0] == '0'
+}
+
+// Len returns the number of values in the sequence, or [math.MaxInt] when the
+// number does not fit in an int, as with `{-9223372036854775808..0}`.
+func (d SequenceData) Len() int {
+	n := d.span()/uint64(d.Step) + 1
+	if n == 0 || n > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(n)
+}
+
+// span returns the distance between the start and end of the sequence. The
+// subtraction wraps, so the distance is exact even when it does not fit in an
+// int.
+func (d SequenceData) span() uint64 {
+	return uint64(max(d.Start, d.End) - min(d.Start, d.End))
+}
+
+// Value returns the i'th value of the sequence. The arithmetic wraps, so the
+// value is exact even when the distance from the start does not fit in an int.
+func (d SequenceData) Value(i int) string {
+	n := d.Start + i*d.Step
+	if d.End < d.Start {
+		n = d.Start - i*d.Step
+	}
+	return d.format(n)
+}
+
+// Contains reports whether s is a value of the sequence.
+func (d SequenceData) Contains(s string) bool {
+	var n int
+	if d.Chars {
+		r, w := utf8.DecodeRuneInString(s)
+		if w == 0 || w != len(s) {
+			return false
+		}
+		n = int(r)
+	} else {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || d.format(n) != s {
+			return false
+		}
+	}
+	lo, hi := min(d.Start, d.End), max(d.Start, d.End)
+	if n < lo || hi < n {
+		return false
+	}
+	// the distance from the start wraps, as with span
+	dist := uint64(n - d.Start)
+	if d.End < d.Start {
+		dist = uint64(d.Start - n)
+	}
+	return dist%uint64(d.Step) == 0
+}
+
+// minLen returns the minimum length in runes of the values of the sequence.
+func (d SequenceData) minLen() int {
+	lo, hi := min(d.Start, d.End), max(d.Start, d.End)
+	switch {
+	case d.Chars:
+		return 1
+	case lo <= 0 && 0 <= hi:
+		return len(d.format(0))
+	case hi < 0:
+		return len(d.format(hi))
+	}
+	return len(d.format(lo))
+}
+
+// maxLen returns the maximum length in bytes of the values of the sequence.
+func (d SequenceData) maxLen() int {
+	if d.Chars {
+		return utf8.UTFMax
+	}
+	return max(len(d.format(d.Start)), len(d.format(d.End)))
+}
+
+// format formats the value n.
+func (d SequenceData) format(n int) string {
+	switch {
+	case d.Chars:
+		return string(rune(n))
+	case d.Width != 0:
+		s := strconv.FormatUint(abs(n), 10)
+		w := d.Width
+		if n < 0 {
+			w--
+		}
+		if len(s) < w {
+			s = strings.Repeat("0", w-len(s)) + s
+		}
+		if n < 0 {
+			s = "-" + s
+		}
+		return s
+	}
+	return strconv.Itoa(n)
+}
+
+// abs returns the absolute value of n, which is exact for [math.MinInt].
+func abs(n int) uint64 {
+	if n < 0 {
+		return -uint64(n)
+	}
+	return uint64(n)
+}
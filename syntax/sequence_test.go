@@ -0,0 +1,134 @@
+package syntax
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	for i, test := range []struct {
+		s      string
+		ok     bool
+		values []string
+	}{
+		{"1..5", true, []string{"1", "2", "3", "4", "5"}},
+		{"5..1..2", true, []string{"5", "3", "1"}},
+		{"1..10..-4", true, []string{"1", "5", "9"}},
+		{"01..03", true, []string{"01", "02", "03"}},
+		{"8..010", true, []string{"008", "009", "010"}},
+		{"-1..1", true, []string{"-1", "0", "1"}},
+		{"-01..01", true, []string{"-01", "000", "001"}},
+		{"a..e..2", true, []string{"a", "c", "e"}},
+		{"z..x", true, []string{"z", "y", "x"}},
+		{"α..γ", true, []string{"α", "β", "γ"}},
+		{"1..a", false, nil},
+		{"ab..c", false, nil},
+		{"1..2..x", false, nil},
+		{"1...2", false, nil},
+		{"1..2..3..4", false, nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			d, ok := parseSequence(test.s)
+			if ok != test.ok {
+				t.Fatalf("expected %t, got: %t", test.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			var values []string
+			for i := range d.Len() {
+				values = append(values, d.Value(i))
+			}
+			if !reflect.DeepEqual(values, test.values) {
+				t.Errorf("expected %q, got: %q", test.values, values)
+			}
+			for _, v := range values {
+				if !d.Contains(v) {
+					t.Errorf("expected %q to be contained", v)
+				}
+			}
+		})
+	}
+}
+
+func TestSequenceLimits(t *testing.T) {
+	for i, test := range []struct {
+		s        string
+		n        int
+		first    string
+		last     string
+		contains []string
+		not      []string
+	}{
+		{
+			"-9223372036854775807..9223372036854775807..2", math.MaxInt,
+			"-9223372036854775807", "",
+			[]string{"-9223372036854775807", "-1", "1", "9223372036854775807"},
+			[]string{"-9223372036854775806", "0", "9223372036854775806"},
+		},
+		{
+			"-9223372036854775807..9223372036854775807..3", 6148914691236517205,
+			"-9223372036854775807", "9223372036854775805",
+			[]string{"-9223372036854775807", "-1", "2", "9223372036854775805"},
+			[]string{"0", "1", "9223372036854775807"},
+		},
+		{
+			"-9223372036854775808..9223372036854775807", math.MaxInt,
+			"-9223372036854775808", "",
+			[]string{"-9223372036854775808", "0", "9223372036854775807"},
+			nil,
+		},
+		{
+			"9223372036854775807..-9223372036854775807..4", 4611686018427387904,
+			"9223372036854775807", "-9223372036854775805",
+			[]string{"9223372036854775807", "3", "-1", "-9223372036854775805"},
+			[]string{"0", "1", "-9223372036854775807"},
+		},
+		{
+			"0..9223372036854775807", math.MaxInt,
+			"0", "",
+			[]string{"0", "9223372036854775807"},
+			[]string{"-1", "9223372036854775808"},
+		},
+		{
+			"1..9223372036854775807", math.MaxInt,
+			"1", "9223372036854775807",
+			[]string{"1", "9223372036854775807"},
+			[]string{"0"},
+		},
+		{
+			"-09223372036854775808..0..9223372036854775807", 2,
+			"-09223372036854775808", "-00000000000000000001",
+			[]string{"-09223372036854775808", "-00000000000000000001"},
+			[]string{"-9223372036854775808", "000000000000000000000"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			d, ok := parseSequence(test.s)
+			if !ok {
+				t.Fatalf("expected ok")
+			}
+			if n := d.Len(); n != test.n {
+				t.Errorf("expected length %d, got: %d", test.n, n)
+			}
+			if v := d.Value(0); v != test.first {
+				t.Errorf("expected first value %q, got: %q", test.first, v)
+			}
+			if v := d.Value(d.Len() - 1); test.last != "" && v != test.last {
+				t.Errorf("expected last value %q, got: %q", test.last, v)
+			}
+			for _, s := range test.contains {
+				if !d.Contains(s) {
+					t.Errorf("expected %q to be contained", s)
+				}
+			}
+			for _, s := range test.not {
+				if d.Contains(s) {
+					t.Errorf("expected %q to not be contained", s)
+				}
+			}
+		})
+	}
+}
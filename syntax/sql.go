@@ -0,0 +1,269 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LikeEscape is the escape character used by the patterns returned by
+// [ToLike], for the ESCAPE clause of a LIKE predicate.
+const LikeEscape = '\\'
+
+// ToLike translates the tree into a SQL LIKE pattern, escaping `%`, `_` and
+// [LikeEscape] with [LikeEscape]. Reports whether the translation is exact,
+// assuming a case-sensitive LIKE. Otherwise the pattern matches a superset of
+// the tree, and the matched values must be filtered with the glob.
+//
+// LIKE has no character sets or alternatives, and its `%` and `_` match
+// separators, so only literal text, `**`, and `*` and `?` without separators
+// are translated exactly.
+func ToLike(node *Node, sep []rune, flags Flags) (string, bool) {
+	b := sqlBuilder{sep: sep, fold: flags&FoldCase != 0, exact: true}
+	return b.build(node, flags)
+}
+
+// ToSQLiteGlob translates the tree into a SQLite GLOB pattern. Reports whether
+// the translation is exact. Otherwise the pattern matches a superset of the
+// tree, and the matched values must be filtered with the glob.
+//
+// GLOB has no alternatives or character classes, and its `*` matches
+// separators, so alternatives, sequences, classes, pattern lists, and `*`
+// with separators are not translated exactly.
+func ToSQLiteGlob(node *Node, sep []rune, flags Flags) (string, bool) {
+	b := sqlBuilder{glob: true, sep: sep, fold: flags&FoldCase != 0, exact: true}
+	return b.build(node, flags)
+}
+
+// sqlBuilder builds a SQL LIKE or SQLite GLOB pattern for a tree.
+type sqlBuilder struct {
+	strings.Builder
+	glob  bool
+	sep   []rune
+	fold  bool
+	exact bool
+	any   bool
+}
+
+// build builds the pattern for the tree, reporting whether it is exact.
+func (b *sqlBuilder) build(node *Node, flags Flags) (string, bool) {
+	b.node(node)
+	if flags&(Pathname|Period|NoDotGlob) != 0 {
+		b.exact = false
+	}
+	if flags&LeadingDir != 0 {
+		b.exact = false
+		b.wildcard()
+	}
+	return b.String(), b.exact
+}
+
+// node writes the pattern for the node.
+func (b *sqlBuilder) node(n *Node) {
+	switch n.Type {
+	case Nothing:
+	case Pattern:
+		for _, c := range n.Children {
+			b.node(c)
+		}
+	case Text:
+		for _, r := range n.Value.(TextData).Text {
+			b.char(r)
+		}
+	case Super:
+		b.wildcard()
+	case Any:
+		b.exact = b.exact && len(b.sep) == 0
+		b.wildcard()
+	case Single:
+		switch {
+		case len(b.sep) == 0:
+			b.single()
+		case b.glob:
+			v := make([][2]rune, len(b.sep))
+			for i, r := range b.sep {
+				v[i] = [2]rune{r, r}
+			}
+			b.set(v, true)
+		default:
+			b.exact = false
+			b.single()
+		}
+	case List, Range, CharSet:
+		if v, not, ok := b.setRanges(n); ok && len(v) != 0 {
+			b.set(v, not)
+			return
+		}
+		b.exact = false
+		b.single()
+	case Class:
+		b.exact = false
+		b.single()
+	default:
+		b.exact = false
+		b.wildcard()
+	}
+}
+
+// char writes the pattern for a literal character.
+func (b *sqlBuilder) char(r rune) {
+	if b.fold && unicode.SimpleFold(r) != r {
+		if b.glob {
+			var v [][2]rune
+			for _, f := range foldRunes(r) {
+				v = append(v, [2]rune{f, f})
+			}
+			b.set(v, false)
+			return
+		}
+		b.exact = false
+		b.single()
+		return
+	}
+	b.any = false
+	switch {
+	case b.glob && strings.ContainsRune("*?[", r):
+		b.WriteString("[" + string(r) + "]")
+	case !b.glob && strings.ContainsRune("%_"+string(LikeEscape), r):
+		b.WriteString(string(LikeEscape) + string(r))
+	default:
+		b.WriteRune(r)
+	}
+}
+
+// wildcard writes the pattern for any sequence of characters.
+func (b *sqlBuilder) wildcard() {
+	if b.any {
+		return
+	}
+	b.any = true
+	if b.glob {
+		b.WriteByte('*')
+	} else {
+		b.WriteByte('%')
+	}
+}
+
+// single writes the pattern for any single character.
+func (b *sqlBuilder) single() {
+	b.any = false
+	if b.glob {
+		b.WriteByte('?')
+	} else {
+		b.WriteByte('_')
+	}
+}
+
+// setRanges returns the character ranges of a set node, and whether the set
+// is negated, when the node can be written as a GLOB set.
+func (b *sqlBuilder) setRanges(n *Node) ([][2]rune, bool, bool) {
+	if !b.glob {
+		return nil, false, false
+	}
+	switch v := n.Value.(type) {
+	case ListData:
+		var rs [][2]rune
+		for _, r := range v.Chars {
+			if !b.fold {
+				rs = append(rs, [2]rune{r, r})
+				continue
+			}
+			for _, f := range foldRunes(r) {
+				rs = append(rs, [2]rune{f, f})
+			}
+		}
+		return rs, v.Not, true
+	case RangeData:
+		if !b.fold {
+			return [][2]rune{{v.Lo, v.Hi}}, v.Not, true
+		}
+	case CharSetData:
+		var rs [][2]rune
+		for _, c := range n.Children {
+			v, not, ok := b.setRanges(c)
+			if !ok || not {
+				return nil, false, false
+			}
+			rs = append(rs, v...)
+		}
+		return rs, v.Not, true
+	}
+	return nil, false, false
+}
+
+// set writes a GLOB set matching any character in the ranges, or any
+// character not in the ranges when not is true.
+func (b *sqlBuilder) set(v [][2]rune, not bool) {
+	b.any = false
+	// `]` is only literal when first, `-` when first or last, and `^` when
+	// not first, so they are split out of the ranges
+	var close, dash, caret bool
+	var s string
+	for _, r := range mergeRanges(v) {
+		for _, c := range splitRange(r, ']', '-', '^') {
+			switch {
+			case c == [2]rune{']', ']'}:
+				close = true
+			case c == [2]rune{'-', '-'}:
+				dash = true
+			case c == [2]rune{'^', '^'}:
+				caret = true
+			case c[0] == c[1]:
+				s += string(c[0])
+			case c[0]+1 == c[1]:
+				s += string(c[0]) + string(c[1])
+			default:
+				s += string(c[0]) + "-" + string(c[1])
+			}
+		}
+	}
+	if caret && !not && !close && !dash && s == "" {
+		b.WriteByte('^')
+		return
+	}
+	b.WriteByte('[')
+	if not {
+		b.WriteByte('^')
+	}
+	if close {
+		b.WriteByte(']')
+	}
+	if caret && !not && !close && s == "" {
+		s, dash = "-", false
+	}
+	b.WriteString(s)
+	if caret {
+		b.WriteByte('^')
+	}
+	if dash {
+		b.WriteByte('-')
+	}
+	b.WriteByte(']')
+}
+
+// splitRange splits the range around each of the characters, which are
+// returned as ranges of their own.
+func splitRange(r [2]rune, chars ...rune) [][2]rune {
+	for _, c := range chars {
+		if r[0] <= c && c <= r[1] {
+			var v [][2]rune
+			if r[0] < c {
+				v = append(v, splitRange([2]rune{r[0], c - 1}, chars...)...)
+			}
+			v = append(v, [2]rune{c, c})
+			if c < r[1] {
+				v = append(v, splitRange([2]rune{c + 1, r[1]}, chars...)...)
+			}
+			return v
+		}
+	}
+	return [][2]rune{r}
+}
+
+// foldRunes returns the characters equivalent to r under simple case folding.
+func foldRunes(r rune) []rune {
+	v := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		v = append(v, f)
+	}
+	return v
+}
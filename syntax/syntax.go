@@ -2,6 +2,7 @@ package syntax
 
 import (
 	"reflect"
+	"unicode/utf8"
 )
 
 // Minimize applies heuristics to minimize the number of nodes in t.
@@ -146,3 +147,93 @@ func Equal(a, b []*Node) bool {
 	}
 	return true
 }
+
+// LiteralPrefix returns the literal text that every string matching the node
+// must start with.
+func LiteralPrefix(n *Node) string {
+	return literalAffix(n, false)
+}
+
+// LiteralSuffix returns the literal text that every string matching the node
+// must end with.
+func LiteralSuffix(n *Node) string {
+	return literalAffix(n, true)
+}
+
+func literalAffix(n *Node, suffix bool) string {
+	switch n.Type {
+	case Text:
+		return n.Value.(TextData).Text
+	case Pattern:
+		var s string
+		for i := range n.Children {
+			c := n.Children[i]
+			if suffix {
+				c = n.Children[len(n.Children)-1-i]
+			}
+			v := literalAffix(c, suffix)
+			if suffix {
+				s = v + s
+			} else {
+				s += v
+			}
+			if !isLiteral(c) {
+				break
+			}
+		}
+		return s
+	case AnyOf, ExactlyOne:
+		var s string
+		for i, c := range n.Children {
+			v := literalAffix(c, suffix)
+			switch {
+			case i == 0:
+				s = v
+			case suffix:
+				s = s[len(s)-commonSuffixLen(s, v):]
+			default:
+				s = s[:commonPrefixLen(s, v)]
+			}
+		}
+		return s
+	}
+	return ""
+}
+
+// isLiteral reports whether the node matches only literal text.
+func isLiteral(n *Node) bool {
+	switch n.Type {
+	case Text, Nothing:
+		return true
+	case Pattern:
+		for _, c := range n.Children {
+			if !isLiteral(c) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func commonPrefixLen(a, b string) int {
+	var i int
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) {
+		i--
+	}
+	return i
+}
+
+func commonSuffixLen(a, b string) int {
+	var i int
+	for i < len(a) && i < len(b) && a[len(a)-1-i] == b[len(b)-1-i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[len(a)-i]) {
+		i--
+	}
+	return i
+}
@@ -125,3 +125,34 @@ func TestCommonChildren(t *testing.T) {
 		})
 	}
 }
+
+func TestLiteralPrefixSuffix(t *testing.T) {
+	for i, test := range []struct {
+		pattern string
+		prefix  string
+		suffix  string
+	}{
+		{"abc", "abc", "abc"},
+		{"abc*def", "abc", "def"},
+		{"*", "", ""},
+		{"src/*/main.go", "src/", "/main.go"},
+		{"{abcd,abce}*{xyz,wyz}", "abc", "yz"},
+		{"{ab,}*", "", ""},
+		{"a{b,c}d*", "a", ""},
+		{"*{b,b}d", "", "bd"},
+		{"{äö,äü}", "ä", ""},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tree, err := Parse(NewLexer(test.pattern))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := LiteralPrefix(tree); s != test.prefix {
+				t.Errorf("expected prefix %q, got: %q", test.prefix, s)
+			}
+			if s := LiteralSuffix(tree); s != test.suffix {
+				t.Errorf("expected suffix %q, got: %q", test.suffix, s)
+			}
+		})
+	}
+}